@@ -0,0 +1,306 @@
+package game_ui
+
+import (
+	"image"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Keyframe sets the values of an animation at At, from 0 (start) to 1 (end).
+// Values that are not set are interpolated between the keyframes around it.
+type Keyframe struct {
+	At                     float64
+	Opacity                *float64
+	TranslateX, TranslateY *float64 // px
+	Scale                  *float64
+	Rotation               *float64 // radian
+	// easing to the next keyframe, the Easing of the animation when nil
+	Easing EasingFunc
+}
+
+const RepeatForever = -1
+
+type Animation struct {
+	Keyframes []Keyframe
+	Duration  int64 // msec
+	Delay     int64 // msec
+	Easing    EasingFunc
+	// times played again after the first, RepeatForever loops
+	Repeat int
+	// plays every other repeat backward
+	Yoyo       bool
+	OnComplete func()
+}
+
+type animationRun struct {
+	animation Animation
+	start     int64
+	started   bool
+	done      bool
+	current   transform
+}
+
+type animatorComponent struct {
+	component Component
+	runs      []*animationRun
+	origin    [2]float64
+	transform transformState
+	// area of the component before the transform
+	childArea image.Rectangle
+}
+
+// Animator plays keyframe animations on the opacity, translation, scale and rotation of a component.
+type Animator = *animatorComponent
+
+func NewAnimator(component Component) Animator {
+	return &animatorComponent{component: component, origin: identityTransform.origin}
+}
+
+// Play starts animation on the next Window.Update, together with the animations already playing.
+func (a Animator) Play(animation Animation) {
+	animation.Keyframes = append([]Keyframe{}, animation.Keyframes...)
+	sort.SliceStable(animation.Keyframes, func(i, j int) bool {
+		return animation.Keyframes[i].At < animation.Keyframes[j].At
+	})
+	a.runs = append(a.runs, &animationRun{animation: animation, current: identityTransform})
+}
+
+// Stop removes every animation, finished ones included, and resets the component.
+func (a Animator) Stop() {
+	a.runs = nil
+}
+
+func (a Animator) IsPlaying() bool {
+	for _, run := range a.runs {
+		if !run.done {
+			return true
+		}
+	}
+	return false
+}
+
+// SetOrigin sets the point the animator scales and rotates around, relative to the size of the component.
+func (a Animator) SetOrigin(x, y float64) {
+	a.origin = [2]float64{x, y}
+}
+
+func keyframeValue(frames []Keyframe, progress float64, easing EasingFunc, get func(frame Keyframe) *float64, fallback float64) float64 {
+	var prev *Keyframe
+	var prevValue = fallback
+	for i := range frames {
+		var value = get(frames[i])
+		if value == nil {
+			continue
+		}
+		if frames[i].At >= progress {
+			if prev == nil || frames[i].At <= prev.At {
+				return *value
+			}
+			var frameEasing = prev.Easing
+			if frameEasing == nil {
+				frameEasing = easing
+			}
+			var rate = (progress - prev.At) / (frames[i].At - prev.At)
+			if frameEasing != nil {
+				rate = frameEasing(rate)
+			}
+			return prevValue + (*value-prevValue)*rate
+		}
+		prev = &frames[i]
+		prevValue = *value
+	}
+	return prevValue
+}
+
+// transformAt returns the values of the animation at progress from 0 to 1.
+func (a Animation) transformAt(progress float64) transform {
+	var t = identityTransform
+	t.opacity = keyframeValue(a.Keyframes, progress, a.Easing, func(f Keyframe) *float64 { return f.Opacity }, 1)
+	t.translate[0] = keyframeValue(a.Keyframes, progress, a.Easing, func(f Keyframe) *float64 { return f.TranslateX }, 0)
+	t.translate[1] = keyframeValue(a.Keyframes, progress, a.Easing, func(f Keyframe) *float64 { return f.TranslateY }, 0)
+	t.scale = keyframeValue(a.Keyframes, progress, a.Easing, func(f Keyframe) *float64 { return f.Scale }, 1)
+	t.rotation = keyframeValue(a.Keyframes, progress, a.Easing, func(f Keyframe) *float64 { return f.Rotation }, 0)
+	return t
+}
+
+// update advances the run to now and reports whether it has just completed.
+func (r *animationRun) update(now int64) bool {
+	if r.done {
+		return false
+	}
+	if !r.started {
+		r.start = now
+		r.started = true
+	}
+	var animation = r.animation
+	var elapsed = now - r.start - animation.Delay
+	if elapsed < 0 {
+		r.current = animation.transformAt(0)
+		return false
+	}
+	var duration = max(animation.Duration, 1)
+	var iteration = elapsed / duration
+	var progress = float64(elapsed%duration) / float64(duration)
+	if animation.Repeat != RepeatForever && iteration > int64(animation.Repeat) {
+		iteration = int64(animation.Repeat)
+		progress = 1
+		r.done = true
+	}
+	if animation.Yoyo && iteration%2 == 1 {
+		progress = 1 - progress
+	}
+	r.current = animation.transformAt(progress)
+	return r.done
+}
+
+func (a Animator) update(now int64) {
+	for _, run := range a.runs {
+		if run.update(now) && run.animation.OnComplete != nil {
+			run.animation.OnComplete()
+		}
+	}
+}
+
+func (a Animator) getTransform() transform {
+	var t = identityTransform
+	t.origin = a.origin
+	for _, run := range a.runs {
+		t = t.then(run.current)
+	}
+	return t
+}
+
+// updateAnimations advances the animators under components.
+func updateAnimations(components []Component, now int64) {
+	walkComponents(components, 0, func(component Component, depth int) {
+		if animator, ok := component.(Animator); ok {
+			animator.update(now)
+		}
+	})
+}
+
+func (a Animator) GetSize() image.Point {
+	return a.component.GetSize()
+}
+
+func (a Animator) Draw(screen *ebiten.Image, x, y int) {
+	a.transform.draw(screen, a.getTransform(), func(target *ebiten.Image, dx, dy int) image.Rectangle {
+		a.component.Draw(target, x+dx, y+dy)
+		a.childArea = a.component.Area()
+		return a.childArea
+	})
+}
+
+func (a Animator) IsFloating() bool {
+	return a.component.IsFloating()
+}
+
+func (a Animator) Components() []Component {
+	return []Component{a.component}
+}
+
+func (a Animator) Area() image.Rectangle {
+	return a.transform.area(a.childArea)
+}
+
+func (a Animator) untransformPoint(point image.Point) image.Point {
+	return a.transform.untransform(point, a.childArea)
+}
+
+func (a Animator) containsPoint(point image.Point) bool {
+	return point.In(a.childArea)
+}
+
+func (a Animator) setLayoutBox(box layoutBox) {
+	if boxed, ok := a.component.(boxedComponent); ok {
+		boxed.setLayoutBox(box)
+	}
+}
+
+func (a Animator) measure() image.Point {
+	return measureComponent(a.component)
+}
+
+func (a Animator) flexFactors() (float32, float32) {
+	if item, ok := a.component.(flexItem); ok {
+		return item.flexFactors()
+	}
+	return 0, 0
+}
+
+func (a Animator) setFixedSize(size *image.Point) {
+	if item, ok := a.component.(flexItem); ok {
+		item.setFixedSize(size)
+	}
+}
+
+func (a Animator) fillsAxis(horizontal bool) bool {
+	if item, ok := a.component.(fillItem); ok {
+		return item.fillsAxis(horizontal)
+	}
+	return false
+}
+
+func (a Animator) positionStyle() ([4]*sizeSeg, AnchorType, int) {
+	return getPositionStyle(a.component)
+}
+
+func (a Animator) Dispose() {
+	a.transform.dispose()
+	if d, ok := a.component.(disposer); ok {
+		d.Dispose()
+	}
+}
+
+// PulseAnimation scales the component up to scale and back, forever.
+func PulseAnimation(duration int64, scale float64) Animation {
+	return Animation{
+		Keyframes: []Keyframe{
+			{At: 0, Scale: Ptr(1.0)},
+			{At: 0.5, Scale: Ptr(scale)},
+			{At: 1, Scale: Ptr(1.0)},
+		},
+		Duration: duration,
+		Easing:   EaseInOutSine,
+		Repeat:   RepeatForever,
+	}
+}
+
+// ShakeAnimation moves the component left and right by distance px.
+func ShakeAnimation(duration int64, distance float64) Animation {
+	return Animation{
+		Keyframes: []Keyframe{
+			{At: 0, TranslateX: Ptr(0.0)},
+			{At: 0.2, TranslateX: Ptr(-distance)},
+			{At: 0.4, TranslateX: Ptr(distance)},
+			{At: 0.6, TranslateX: Ptr(-distance / 2)},
+			{At: 0.8, TranslateX: Ptr(distance / 2)},
+			{At: 1, TranslateX: Ptr(0.0)},
+		},
+		Duration: duration,
+	}
+}
+
+// SlideInAnimation fades the component in from dx, dy px away.
+func SlideInAnimation(duration int64, dx, dy float64) Animation {
+	return Animation{
+		Keyframes: []Keyframe{
+			{At: 0, TranslateX: Ptr(dx), TranslateY: Ptr(dy), Opacity: Ptr(0.0)},
+			{At: 1, TranslateX: Ptr(0.0), TranslateY: Ptr(0.0), Opacity: Ptr(1.0)},
+		},
+		Duration: duration,
+		Easing:   EaseOut,
+	}
+}
+
+// FadeOutAnimation fades the component out, it stays hidden until the animator is stopped.
+func FadeOutAnimation(duration int64) Animation {
+	return Animation{
+		Keyframes: []Keyframe{
+			{At: 0, Opacity: Ptr(1.0)},
+			{At: 1, Opacity: Ptr(0.0)},
+		},
+		Duration: duration,
+	}
+}
@@ -23,6 +23,28 @@ type Component interface {
 	Area() image.Rectangle
 }
 
+type disposer interface {
+	Dispose()
+}
+
+// layoutBox is the space a parent offers to its children.
+type layoutBox struct {
+	screen image.Point
+	// available content box of the parent
+	parent image.Point
+	// maximum content size imposed by MaxWidth/MaxHeight of an ancestor, 0 is unlimited
+	limit image.Point
+}
+
+type boxedComponent interface {
+	setLayoutBox(box layoutBox)
+}
+
+func screenBox(screen *ebiten.Image) layoutBox {
+	var size = screen.Bounds().Size()
+	return layoutBox{screen: size, parent: size}
+}
+
 func init() {
 	emptyImage.Fill(color.White)
 }
@@ -39,6 +61,12 @@ const (
 	px sizeType = 0
 	vw sizeType = 1
 	vh sizeType = 2
+	fr sizeType = 3
+	pw sizeType = 4
+	ph sizeType = 5
+	// auto and fill are only meaningful as Width/Height
+	auto sizeType = 6
+	fill sizeType = 7
 )
 
 func Px(value int, calc ...sizeSeg) *sizeSeg {
@@ -50,23 +78,73 @@ func Vw(value float32, calc ...sizeSeg) *sizeSeg {
 func Vh(value float32, calc ...sizeSeg) *sizeSeg {
 	return &sizeSeg{vh, value, calc}
 }
+func Fr(value float32) *sizeSeg {
+	return &sizeSeg{fr, value, nil}
+}
+func Pw(value float32, calc ...sizeSeg) *sizeSeg {
+	return &sizeSeg{pw, value, calc}
+}
+func Ph(value float32, calc ...sizeSeg) *sizeSeg {
+	return &sizeSeg{ph, value, calc}
+}
+func Auto() *sizeSeg {
+	return &sizeSeg{auto, 0, nil}
+}
+func Fill(calc ...sizeSeg) *sizeSeg {
+	return &sizeSeg{fill, 0, calc}
+}
+
+func Ptr[T any](value T) *T {
+	return &value
+}
 
-func calcSize(screenSize image.Point, size sizeSeg) int {
+func calcSize(box layoutBox, size sizeSeg) int {
 	var calc = 0
 	for _, size := range size.calc {
-		calc += calcSize(screenSize, size)
+		calc += calcSize(box, size)
 	}
 	switch size.t {
 	case px:
 		return int(size.v) + calc
 	case vw:
-		return int(float32(screenSize.X)*size.v) + calc
+		return int(float32(box.screen.X)*size.v) + calc
 	case vh:
-		return int(float32(screenSize.Y)*size.v) + calc
+		return int(float32(box.screen.Y)*size.v) + calc
+	case pw:
+		return int(float32(box.parent.X)*size.v) + calc
+	case ph:
+		return int(float32(box.parent.Y)*size.v) + calc
+	case fill:
+		return calc
 	}
 	return 0
 }
 
+// calcLength resolves Width or Height, ok is false when the size depends on the content.
+func calcLength(box layoutBox, size *sizeSeg, horizontal bool) (int, bool) {
+	if size == nil || size.t == auto {
+		return 0, false
+	}
+	if size.t == fill {
+		if horizontal {
+			return box.parent.X + calcSize(box, *size), true
+		}
+		return box.parent.Y + calcSize(box, *size), true
+	}
+	return calcSize(box, *size), true
+}
+
+func limitLength(limit, length int) int {
+	if limit <= 0 {
+		return length
+	}
+	return min(limit, length)
+}
+
+func isFill(size *sizeSeg) bool {
+	return size != nil && size.t == fill
+}
+
 func Size1(size *sizeSeg) *[4]sizeSeg {
 	return &[4]sizeSeg{*size, *size, *size, *size}
 }
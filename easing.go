@@ -0,0 +1,50 @@
+package game_ui
+
+import "math"
+
+// EasingFunc maps the progress t of an animation in [0, 1] to its eased progress.
+type EasingFunc func(t float64) float64
+
+func EaseLinear(t float64) float64 {
+	return t
+}
+
+func EaseIn(t float64) float64 {
+	return t * t * t
+}
+
+func EaseOut(t float64) float64 {
+	return 1 - math.Pow(1-t, 3)
+}
+
+func EaseInOut(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	return 1 - math.Pow(-2*t+2, 3)/2
+}
+
+// EaseOutBack overshoots the end a little before settling.
+func EaseOutBack(t float64) float64 {
+	const c1 = 1.70158
+	const c3 = c1 + 1
+	return 1 + c3*math.Pow(t-1, 3) + c1*math.Pow(t-1, 2)
+}
+
+func EaseInOutSine(t float64) float64 {
+	return -(math.Cos(math.Pi*t) - 1) / 2
+}
+
+// ease returns the eased progress of elapsed msec out of duration, clamped to [0, 1].
+func ease(easing EasingFunc, elapsed, duration int64) float64 {
+	if duration <= 0 || elapsed >= duration {
+		return 1
+	}
+	if elapsed <= 0 {
+		return 0
+	}
+	if easing == nil {
+		easing = EaseLinear
+	}
+	return easing(float64(elapsed) / float64(duration))
+}
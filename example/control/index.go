@@ -3,6 +3,7 @@ package control
 import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/yiozio/game-ui"
 )
 
 type Mode = int
@@ -15,10 +16,13 @@ const (
 
 func UpdateControlMode(current Mode) Mode {
 	if current != Mouse && inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		game_ui.SetInputDevice(game_ui.DeviceKeyboard)
 		return Mouse
 	} else if touchedIDs := inpututil.AppendJustPressedTouchIDs(nil); len(touchedIDs) > 0 && current != Touch {
+		game_ui.SetInputDevice(game_ui.DeviceKeyboard)
 		return Touch
 	} else if gid := GetGamepadId(); gid != nil && current != Gamepad && len(inpututil.AppendJustPressedStandardGamepadButtons(*gid, nil)) > 0 {
+		game_ui.SetInputDevice(game_ui.GamepadDevice(*gid))
 		return Gamepad
 	}
 	return current
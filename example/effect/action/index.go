@@ -1,26 +1,42 @@
 package action
 
 import (
-	"image/color"
-
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/yiozio/game-ui"
 )
 
-var x, y int = 0, 0
-var effectAt int64 = 0
+const radius = 30
 
-func StartEffect(now int64) {
-	x, y = ebiten.CursorPosition()
-	effectAt = now
-}
+var ring = game_ui.NewView([]game_ui.Component{}, game_ui.ViewStyle{
+	IsFloating:  true,
+	AnchorTo:    game_ui.Ptr(game_ui.AnchorScreen),
+	Width:       game_ui.Px(radius * 2),
+	Height:      game_ui.Px(radius * 2),
+	Radius:      game_ui.Radius1(radius),
+	BorderWidth: game_ui.Size1(game_ui.Px(3)),
+	BorderColor: game_ui.ColorCode1(0xffffffff),
+})
+
+var effect = game_ui.NewAnimator(ring)
+
+// Window shows the action effect above the scenes, call its Update and Draw every frame.
+var Window = game_ui.NewWindow([]game_ui.Component{})
+
+func StartEffect() {
+	var x, y = ebiten.CursorPosition()
+	ring.ReplaceStyle(0, game_ui.ViewStyle{Left: game_ui.Px(x - radius), Top: game_ui.Px(y - radius)})
 
-func DrawEffect(screen *ebiten.Image, now int64) {
-	const msec = 200
-	if (now - effectAt) < msec {
-		const maxR = 30
-		var rate = float32(now-effectAt) / msec
-		var r = rate * maxR
-		vector.StrokeCircle(screen, float32(x), float32(y), r, 4*(1-rate), color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: uint8(0xff * rate)}, false)
-	}
+	effect.Stop()
+	Window.RemoveFromLayer(game_ui.LayerOverlay, effect)
+	Window.AddToLayer(game_ui.LayerOverlay, effect)
+	effect.Play(game_ui.Animation{
+		Keyframes: []game_ui.Keyframe{
+			{At: 0, Scale: game_ui.Ptr(0.0), Opacity: game_ui.Ptr(1.0)},
+			{At: 1, Scale: game_ui.Ptr(1.0), Opacity: game_ui.Ptr(0.0)},
+		},
+		Duration: 200,
+		OnComplete: func() {
+			Window.RemoveFromLayer(game_ui.LayerOverlay, effect)
+		},
+	})
 }
@@ -34,12 +34,13 @@ func (g *Game) Update() error {
 		scene.Instance = start.NewScene()
 	}
 	scene.Instance.Update(g.now, g.screenSize, g.mode)
+	action.Window.Update(g.now)
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
 	scene.Instance.Draw(screen, g.now, g.screenSize, g.mode)
-	action.DrawEffect(screen, g.now)
+	action.Window.Draw(screen, 0, 0)
 }
 
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
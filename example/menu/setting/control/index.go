@@ -16,23 +16,31 @@ import (
 type Menu struct {
 	game_ui.Window
 	buttonMapping      gamepad.ButtonMapping
-	selectedMenuIndex  int
 	inputWaitMenuIndex int
 	initialized        bool
-}
-
-func toP[T string](str T) *T {
-	return &str
+	mode               control.Mode
 }
 
 func NewSettingMenu() *Menu {
-	return &Menu{game_ui.NewWindow([]game_ui.Component{settingWindow}), gamepad.CurrentButtonMapping, -1, -1, false}
+	var m = &Menu{
+		Window:             game_ui.NewWindow([]game_ui.Component{modalDimmer, settingContainer}),
+		buttonMapping:      gamepad.CurrentButtonMapping,
+		inputWaitMenuIndex: -1,
+	}
+	for i, item := range settingMenuItems {
+		var index = i
+		item.SetFocusable(true)
+		item.OnClick(func(event game_ui.PointerEvent) {
+			m.action(index)
+		})
+	}
+	return m
 }
 
 func (m *Menu) Update(now int64, screenSize image.Point, mode control.Mode, enable bool) {
-	if mode == control.Gamepad && m.selectedMenuIndex < 0 {
-		m.selectedMenuIndex = 0
-	}
+	m.mode = mode
+	var focus = m.Window.FocusManager()
+	focus.SetBindings(menu.FocusBindings(m.buttonMapping))
 	if !m.initialized {
 		gamepadUpSettingMenuKeyText.ChangeText(control.ButtonLabel.Up)
 		gamepadUpSettingMenuValueText.ChangeText(gamepad.ButtonToString(m.buttonMapping.Up))
@@ -43,47 +51,35 @@ func (m *Menu) Update(now int64, screenSize image.Point, mode control.Mode, enab
 	}
 
 	if gid := control.GetGamepadId(); gid != nil {
-		var name = ebiten.GamepadName(*gid)
-		if len(name) > 17 {
-			name = name[0:17] + "…"
-		}
-		gamepadSettingMenuValueText.ChangeText(name)
+		gamepadSettingMenuValueText.ChangeText(ebiten.GamepadName(*gid))
 	} else {
 		gamepadSettingMenuValueText.ChangeText("None")
 	}
 
+	if mode == control.Gamepad && focus.Focused() == nil {
+		focus.Focus(settingMenuItems[0])
+	}
 	if m.inputWaitMenuIndex >= 0 {
 		inputWait(m)
 	} else {
-		controlMenu(m, mode, now)
+		m.Window.Update(now)
 	}
+	m.updateStyles()
 
 	m.initialized = true
 }
 
-func (m *Menu) Draw(screen *ebiten.Image, now int64, screenSize image.Point, mode control.Mode) {
-	// draw window
+func (m *Menu) updateStyles() {
 	for i := range settingMenuItems {
-		if i == m.inputWaitMenuIndex {
-			settingMenuItems[i].ReplaceStyle(0, game_ui.ViewStyle{
-				BorderColor: game_ui.ColorCode1(0xffffffff),
-				BorderWidth: game_ui.Size4(game_ui.Px(0), game_ui.Px(0), game_ui.Px(1), game_ui.Px(10)),
-			})
-		} else if i == m.selectedMenuIndex {
-			settingMenuItems[i].ReplaceStyle(0, selectedMenuItemStyle)
-		} else {
-			settingMenuItems[i].ReplaceStyle(0, game_ui.ViewStyle{})
-		}
+		settingMenuItems[i].SetSelected(i == m.inputWaitMenuIndex)
 	}
-	var size = m.Window.GetSize()
-	m.Window.Draw(screen, (screenSize.X-size.X-2)/2, (screenSize.Y-size.Y-2)/2)
 }
 
 func inputWait(m *Menu) {
 	if gid := control.GetGamepadId(); gid != nil && m.initialized {
 		var buttons = inpututil.AppendJustPressedStandardGamepadButtons(*gid, nil)
 		if len(buttons) > 0 {
-			switch m.selectedMenuIndex {
+			switch m.inputWaitMenuIndex {
 			case 0:
 				m.buttonMapping.Up = buttons[0]
 				gamepadUpSettingMenuValueText.ChangeText(gamepad.ButtonToString(m.buttonMapping.Up))
@@ -101,62 +97,29 @@ func inputWait(m *Menu) {
 	}
 }
 
-func controlMenu(m *Menu, mode control.Mode, now int64) {
-	if mode != control.Gamepad {
-		if m.selectedMenuIndex >= 0 {
-			settingMenuItems[m.selectedMenuIndex].ReplaceStyle(0, game_ui.ViewStyle{})
-			m.selectedMenuIndex = -1
-		}
+func (m *Menu) action(index int) {
+	if m.mode == control.Mouse {
+		actionEffect.StartEffect()
 	}
-	var action = false
-	if mode == control.Mouse {
-		m.selectedMenuIndex, _ = menu.FindHoveredCell(settingMenuItems)
-		action = inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
-	} else if mode == control.Touch {
-	} else if mode == control.Gamepad {
-		if m.selectedMenuIndex < 0 {
-			m.selectedMenuIndex = 0
-		}
-
-		if gid := control.GetGamepadId(); gid != nil && m.initialized {
-			if inpututil.IsStandardGamepadButtonJustPressed(*gid, m.buttonMapping.Up) {
-				settingMenuItems[m.selectedMenuIndex].ReplaceStyle(0, game_ui.ViewStyle{})
-				m.selectedMenuIndex += len(settingMenuItems) - 1
-			} else if inpututil.IsStandardGamepadButtonJustPressed(*gid, m.buttonMapping.Down) {
-				settingMenuItems[m.selectedMenuIndex].ReplaceStyle(0, game_ui.ViewStyle{})
-				m.selectedMenuIndex += 1
-			}
-			m.selectedMenuIndex = m.selectedMenuIndex % len(settingMenuItems)
-
-			action = inpututil.IsStandardGamepadButtonJustPressed(*gid, m.buttonMapping.Action)
-		}
+	if index < 3 {
+		m.inputWaitMenuIndex = index
 	}
-
-	if m.selectedMenuIndex >= 0 {
-		var view = settingMenuItems[m.selectedMenuIndex]
-		view.ReplaceStyle(0, selectedMenuItemStyle)
-		if action {
-			if mode == control.Mouse {
-				actionEffect.StartEffect(now)
-			}
-			if m.selectedMenuIndex < 3 {
-				m.inputWaitMenuIndex = m.selectedMenuIndex
-			}
-			switch m.selectedMenuIndex {
-			case 0:
-				gamepadUpSettingMenuValueText.ChangeText("...")
-			case 1:
-				gamepadDownSettingMenuValueText.ChangeText("...")
-			case 2:
-				gamepadActionSettingMenuValueText.ChangeText("...")
-			case 3:
-				gamepad.CurrentButtonMapping = m.buttonMapping
-				m.initialized = false
-				setting.Opened = nil
-			case 16:
-				m.initialized = false
-				setting.Opened = nil
-			}
-		}
+	switch index {
+	case 0:
+		gamepadUpSettingMenuValueText.ChangeText("...")
+	case 1:
+		gamepadDownSettingMenuValueText.ChangeText("...")
+	case 2:
+		gamepadActionSettingMenuValueText.ChangeText("...")
+	case 3:
+		gamepad.CurrentButtonMapping = m.buttonMapping
+		m.close()
 	}
 }
+
+// close releases the focus of the shared menu items before the menu is dropped.
+func (m *Menu) close() {
+	m.Window.FocusManager().Blur()
+	m.initialized = false
+	setting.Opened = nil
+}
@@ -14,20 +14,48 @@ var settingWindow = game_ui.NewView([]game_ui.Component{
 	BorderColor:      game_ui.ColorCode1(0xffffff88),
 	Radius:           game_ui.Radius1(11),
 	Padding:          game_ui.Size2(game_ui.Px(10), game_ui.Px(20)),
-	PositionVertical: toP(game_ui.Center),
+	PositionVertical: game_ui.Ptr(game_ui.Center),
+	BoxShadow: game_ui.Shadows(
+		game_ui.BoxShadow{Y: 6, Blur: 16, Color: game_ui.Color(0x00000099)},
+		game_ui.BoxShadow{Blur: 8, Color: game_ui.Color(0xffffff33), Inset: true},
+	),
+})
+
+var modalDimmer = game_ui.NewView([]game_ui.Component{}, game_ui.ViewStyle{
+	IsFloating:      true,
+	Top:             game_ui.Px(0),
+	Right:           game_ui.Px(0),
+	Bottom:          game_ui.Px(0),
+	Left:            game_ui.Px(0),
+	BackgroundColor: game_ui.ColorCode1(0x00000066),
+})
+
+var settingContainer = game_ui.NewView([]game_ui.Component{settingWindow}, game_ui.ViewStyle{
+	Width:              game_ui.Vw(1),
+	Height:             game_ui.Vh(1),
+	PositionHorizontal: game_ui.Ptr(game_ui.Center),
+	PositionVertical:   game_ui.Ptr(game_ui.Center),
 })
 
 var settingMenuItemStyle = game_ui.ViewStyle{
 	Margin:      game_ui.Size2(game_ui.Px(5), game_ui.Px(0)),
-	Width:       game_ui.Px(50),
+	Width:       game_ui.Px(180),
 	Padding:     game_ui.Size4(game_ui.Px(2), game_ui.Px(5), game_ui.Px(0), game_ui.Px(5)),
 	BorderWidth: game_ui.Size4(game_ui.Px(0), game_ui.Px(0), game_ui.Px(1), game_ui.Px(0)),
 	BorderColor: game_ui.ColorCode1(0x00000000),
-	Direction:   toP(game_ui.Horizontal),
+	Direction:   game_ui.Ptr(game_ui.Horizontal),
+	Gap:         game_ui.Px(10),
+	Focused:     &focusedMenuItemStyle,
+	Selected:    &waitingMenuItemStyle,
 }
 
 var gamepadSettingMenuKeyText = game_ui.NewText("GAMEPAD: ", game_ui.TextStyle{Color: game_ui.Color(0x00aaaaff)})
-var gamepadSettingMenuValueText = game_ui.NewText("None", game_ui.TextStyle{Color: game_ui.Color(0x00aaaaff)})
+var gamepadSettingMenuValueText = game_ui.NewText("None", game_ui.TextStyle{
+	Color:    game_ui.Color(0x00aaaaff),
+	Width:    game_ui.Px(108),
+	MaxLines: game_ui.Ptr(1),
+	Overflow: game_ui.Ptr(game_ui.Ellipsis),
+})
 var gamepadUpSettingMenuKeyText = game_ui.NewText("GAMEPAD-UP: ")
 var gamepadUpSettingMenuValueText = game_ui.NewText("")
 var gamepadDownSettingMenuKeyText = game_ui.NewText("GAMEPAD-DOWN: ")
@@ -35,16 +63,14 @@ var gamepadDownSettingMenuValueText = game_ui.NewText("")
 var gamepadActionSettingMenuKeyText = game_ui.NewText("GAMEPAD-ACTION: ")
 var gamepadActionSettingMenuValueText = game_ui.NewText("")
 
-var gamepadSettingMenuView = game_ui.NewView([]game_ui.Component{
-	game_ui.NewView([]game_ui.Component{gamepadSettingMenuKeyText}, game_ui.ViewStyle{Width: game_ui.Px(60)}),
-	game_ui.NewView([]game_ui.Component{gamepadSettingMenuValueText}, game_ui.ViewStyle{Width: game_ui.Px(110), PositionHorizontal: toP(game_ui.Last)}),
-}, settingMenuItemStyle)
+var menuTitleStyle = game_ui.ViewStyle{
+	PositionHorizontal: game_ui.Ptr(game_ui.SpaceBetween),
+}
+
+var gamepadSettingMenuView = menuTitleView([]game_ui.Component{gamepadSettingMenuKeyText, gamepadSettingMenuValueText})
 
 func menuTitleView(components []game_ui.Component) game_ui.View {
-	return game_ui.NewView([]game_ui.Component{
-		game_ui.NewView([]game_ui.Component{components[0]}, game_ui.ViewStyle{Width: game_ui.Px(140)}),
-		game_ui.NewView([]game_ui.Component{components[1]}, game_ui.ViewStyle{Width: game_ui.Px(30), PositionHorizontal: toP(game_ui.Last)}),
-	}, settingMenuItemStyle)
+	return game_ui.NewView(components, settingMenuItemStyle, menuTitleStyle)
 }
 
 var gamepadUpSettingMenuView = menuTitleView([]game_ui.Component{gamepadUpSettingMenuKeyText, gamepadUpSettingMenuValueText})
@@ -59,6 +85,12 @@ var settingMenuItems = []game_ui.View{
 	game_ui.NewView([]game_ui.Component{closeSettingMenuText}, settingMenuItemStyle),
 }
 
-var selectedMenuItemStyle = game_ui.ViewStyle{
+var focusedMenuItemStyle = game_ui.ViewStyle{
+	BorderColor: game_ui.ColorCode1(0xffffffff),
+}
+
+// waitingMenuItemStyle marks the item waiting for a gamepad button
+var waitingMenuItemStyle = game_ui.ViewStyle{
 	BorderColor: game_ui.ColorCode1(0xffffffff),
+	BorderWidth: game_ui.Size4(game_ui.Px(0), game_ui.Px(0), game_ui.Px(1), game_ui.Px(10)),
 }
@@ -5,7 +5,6 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/yiozio/game-ui"
 	"github.com/yiozio/game-ui/example/control"
@@ -18,15 +17,15 @@ import (
 
 type Menu struct {
 	game_ui.Window
-	selectedMenuIndex int
-	initialized       bool
-	startFlag         bool
-	exitFlag          bool
+	popup     setting.Menu
+	mode      control.Mode
+	startFlag bool
+	exitFlag  bool
 }
 
 func NewStartMenu() *Menu {
 	var pos = game_ui.Center
-	return &Menu{game_ui.NewWindow([]game_ui.Component{game_ui.NewView([]game_ui.Component{
+	var m = &Menu{Window: game_ui.NewWindow([]game_ui.Component{game_ui.NewView([]game_ui.Component{
 		titleView,
 		startView,
 		settingView,
@@ -35,72 +34,52 @@ func NewStartMenu() *Menu {
 		Width:            game_ui.Vw(1),
 		Height:           game_ui.Vh(1),
 		PositionVertical: &pos,
-	})}), -1, false, false, false}
+	})})}
+
+	var actions = []func(){
+		func() {
+			m.startFlag = !m.startFlag
+			if m.startFlag {
+				titleText.ChangeText("Start")
+			} else {
+				titleText.ChangeText("Sample")
+			}
+		},
+		func() {
+			setting.Opened = controlMenu.NewSettingMenu()
+		},
+		func() {
+			m.exitFlag = !m.exitFlag
+		},
+	}
+	for i, item := range startMenuItems {
+		var action = actions[i]
+		item.SetFocusable(true)
+		item.OnClick(func(event game_ui.PointerEvent) {
+			if m.mode == control.Mouse {
+				actionEffect.StartEffect()
+			}
+			action()
+		})
+	}
+	return m
 }
 
 func (m *Menu) Update(now int64, screenSize image.Point, mode control.Mode, enable bool) {
+	defer m.syncPopup()
 	if setting.Opened != nil {
+		m.syncPopup()
 		setting.Opened.Update(now, screenSize, mode, enable)
 		return
 	}
+	m.mode = mode
 
-	if mode == control.Gamepad && m.selectedMenuIndex < 0 {
-		m.selectedMenuIndex = 0
-	}
-
-	for _, v := range startMenuItems {
-		v.ReplaceStyle(0, game_ui.ViewStyle{})
-	}
-
-	if mode != control.Gamepad {
-		m.selectedMenuIndex = -1
-	}
-	var action = false
-	if mode == control.Mouse {
-		m.selectedMenuIndex, _ = menu.FindHoveredCell(startMenuItems)
-		action = inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
-	} else if mode == control.Touch {
-	} else if mode == control.Gamepad {
-		if m.selectedMenuIndex < 0 {
-			m.selectedMenuIndex = 0
-		}
-
-		if gid := control.GetGamepadId(); gid != nil && m.initialized {
-			if inpututil.IsStandardGamepadButtonJustPressed(*gid, gamepad.CurrentButtonMapping.Up) {
-				startMenuItems[m.selectedMenuIndex].ReplaceStyle(0, game_ui.ViewStyle{})
-				m.selectedMenuIndex += len(startMenuItems) - 1
-			} else if inpututil.IsStandardGamepadButtonJustPressed(*gid, gamepad.CurrentButtonMapping.Down) {
-				startMenuItems[m.selectedMenuIndex].ReplaceStyle(0, game_ui.ViewStyle{})
-				m.selectedMenuIndex += 1
-			}
-			m.selectedMenuIndex = m.selectedMenuIndex % len(startMenuItems)
-
-			action = inpututil.IsStandardGamepadButtonJustPressed(*gid, gamepad.CurrentButtonMapping.Action)
-		}
-	}
-
-	if m.selectedMenuIndex >= 0 {
-		startMenuItems[m.selectedMenuIndex].ReplaceStyle(0, game_ui.ViewStyle{BorderColor: game_ui.ColorCode1(0xffffffff)})
-		if action {
-			if mode == control.Mouse {
-				actionEffect.StartEffect(now)
-			}
-			switch m.selectedMenuIndex {
-			case 0:
-				m.startFlag = !m.startFlag
-				if m.startFlag {
-					titleText.ChangeText("Start")
-				} else {
-					titleText.ChangeText("Sample")
-				}
-			case 1:
-				setting.Opened = controlMenu.NewSettingMenu()
-			case 2:
-				m.exitFlag = !m.exitFlag
-			}
-		}
+	var focus = m.Window.FocusManager()
+	focus.SetBindings(menu.FocusBindings(gamepad.CurrentButtonMapping))
+	if mode == control.Gamepad && focus.Focused() == nil {
+		focus.Focus(startMenuItems[0])
 	}
-	m.initialized = true
+	m.Window.Update(now)
 }
 
 func (m *Menu) Draw(screen *ebiten.Image, now int64, screenSize image.Point, mode control.Mode) {
@@ -113,31 +92,24 @@ func (m *Menu) Draw(screen *ebiten.Image, now int64, screenSize image.Point, mod
 		vector.FillRect(screen, 0, 0, 640, 480, c, false)
 	}
 
-	var n = 0xe * uint32((now/2)%16)
-	if n > (0xe * 8) {
-		n = 0xd*16 - n
-	}
-	n -= 1
-	var bColor1 uint32 = 0xffffff90 + n
-	var bColor2 uint32 = 0xffffff00
-	var bgColor1 uint32 = 0x5599cc50 + n
-	var bgColor2 uint32 = 0x5599cc00
-
 	// draw window
-	for i := range startMenuItems {
-		if i == m.selectedMenuIndex && setting.Opened == nil {
-			startMenuItems[i].ReplaceStyle(0, game_ui.ViewStyle{
-				BorderColor:     game_ui.ColorCodeHorizontal(bColor1, bColor2),
-				BackgroundColor: game_ui.ColorCodeHorizontal(bgColor1, bgColor2),
-			})
-		} else {
-			startMenuItems[i].PopStyle()
-		}
-	}
-
 	m.Window.Draw(screen, 0, 0)
+}
 
+// syncPopup shows the opened setting menu on the popup layer and keeps the focus inside it.
+func (m *Menu) syncPopup() {
+	if setting.Opened == m.popup {
+		return
+	}
+	var focus = m.Window.FocusManager()
+	if m.popup != nil {
+		focus.PopScope()
+	}
+	m.popup = setting.Opened
 	if setting.Opened != nil {
-		setting.Opened.Draw(screen, now, screenSize, mode)
+		m.Window.SetLayer(game_ui.LayerPopup, []game_ui.Component{setting.Opened})
+		focus.PushScope(setting.Opened)
+	} else {
+		m.Window.SetLayer(game_ui.LayerPopup, nil)
 	}
 }
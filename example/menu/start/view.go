@@ -1,27 +1,24 @@
 package start
 
 import (
+	"bytes"
+
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/yiozio/game-ui"
-	"golang.org/x/image/font"
-	"golang.org/x/image/font/opentype"
 )
 
-var tt, _ = opentype.Parse(fonts.MPlus1pRegular_ttf)
-var face, _ = opentype.NewFace(tt, &opentype.FaceOptions{
-	Size:    32,
-	DPI:     72,
-	Hinting: font.HintingVertical,
-})
+var source, _ = text.NewGoTextFaceSource(bytes.NewReader(fonts.MPlus1pRegular_ttf))
 
-var titleFont = game_ui.NewTextFont(
-	face,
-	0,
-	0,
-)
+var titleFont = game_ui.NewTextFaces(&text.GoTextFace{Source: source, Size: 32})
 
 var titleText = game_ui.NewText("SAMPLE", game_ui.TextStyle{
-	Font: &titleFont,
+	Font:        &titleFont,
+	LineHeight:  game_ui.Px(40),
+	Fill:        game_ui.ColorCodeVertical(0xffffffff, 0x99ccffff),
+	StrokeWidth: game_ui.Ptr(2),
+	StrokeColor: game_ui.Color(0x113366ff),
+	Shadow:      &game_ui.TextShadow{Y: 3, Blur: 4, Color: game_ui.Color(0x00000099)},
 })
 var titleView = game_ui.NewView([]game_ui.Component{titleText}, game_ui.ViewStyle{Margin: game_ui.Size3(game_ui.Px(10), game_ui.Px(50), game_ui.Px(20))})
 
@@ -33,6 +30,11 @@ var startMenuItemStyle = game_ui.ViewStyle{
 	BorderColor:     game_ui.ColorCode1(0x00000000),
 	BackgroundColor: game_ui.ColorCode1(0x00000000),
 	Radius:          game_ui.Radius4(20, 0, 0, 20),
+	Transition:      &game_ui.Transition{Duration: 200, Easing: game_ui.EaseOut},
+	Focused: &game_ui.ViewStyle{
+		BorderColor:     game_ui.ColorCodeHorizontal(0xffffffdf, 0xffffff00),
+		BackgroundColor: game_ui.ColorCodeHorizontal(0x5599cc9f, 0x5599cc00),
+	},
 }
 
 var startText = game_ui.NewText("START")
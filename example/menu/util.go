@@ -1,24 +1,16 @@
 package menu
 
 import (
-	"image"
-
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/yiozio/game-ui"
+	"github.com/yiozio/game-ui/example/control/gamepad"
 )
 
-func FindHoveredCell(cells []game_ui.View) (int, game_ui.View) {
-	var x, y = ebiten.CursorPosition()
-	for j, c := range cells {
-		var area = c.Area()
-		if CheckPointInArea(x, y, area.Min, area.Max) {
-			return j, c
-		}
-	}
-
-	return -1, nil
-}
-
-func CheckPointInArea(px, py int, minPoint, maxPoint image.Point) bool {
-	return minPoint.X <= px && px <= maxPoint.X && minPoint.Y <= py && py <= maxPoint.Y
+// FocusBindings returns the default focus bindings with the gamepad buttons of mapping.
+func FocusBindings(mapping gamepad.ButtonMapping) game_ui.FocusBindings {
+	var bindings = game_ui.DefaultFocusBindings
+	bindings.GamepadUp = []ebiten.StandardGamepadButton{mapping.Up}
+	bindings.GamepadDown = []ebiten.StandardGamepadButton{mapping.Down}
+	bindings.GamepadActivate = []ebiten.StandardGamepadButton{mapping.Action}
+	return bindings
 }
@@ -0,0 +1,393 @@
+package game_ui
+
+import (
+	"image"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+type FocusDirection = string
+
+const (
+	FocusUp    FocusDirection = "up"
+	FocusRight FocusDirection = "right"
+	FocusDown  FocusDirection = "down"
+	FocusLeft  FocusDirection = "left"
+)
+
+// FocusBindings are the keys and standard gamepad buttons that move and activate the focus.
+// Next moves backward while shift is held.
+type FocusBindings struct {
+	Up, Right, Down, Left []ebiten.Key
+	Next, Activate        []ebiten.Key
+
+	GamepadUp, GamepadRight, GamepadDown, GamepadLeft []ebiten.StandardGamepadButton
+	GamepadNext, GamepadPrev, GamepadActivate         []ebiten.StandardGamepadButton
+}
+
+var DefaultFocusBindings = FocusBindings{
+	Up:       []ebiten.Key{ebiten.KeyArrowUp},
+	Right:    []ebiten.Key{ebiten.KeyArrowRight},
+	Down:     []ebiten.Key{ebiten.KeyArrowDown},
+	Left:     []ebiten.Key{ebiten.KeyArrowLeft},
+	Next:     []ebiten.Key{ebiten.KeyTab},
+	Activate: []ebiten.Key{ebiten.KeyEnter, ebiten.KeySpace},
+
+	GamepadUp:       []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftTop},
+	GamepadRight:    []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftRight},
+	GamepadDown:     []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftBottom},
+	GamepadLeft:     []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonLeftLeft},
+	GamepadNext:     []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonFrontTopRight},
+	GamepadPrev:     []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonFrontTopLeft},
+	GamepadActivate: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightBottom},
+}
+
+var defaultFocusStyle = ViewStyle{
+	BorderColor: ColorCode1(0xffffffff),
+}
+
+const (
+	focusRepeatDelay    = 24 // ticks before a held binding repeats
+	focusRepeatInterval = 6  // ticks
+)
+
+type focusHandlers struct {
+	focus, blur func()
+}
+
+type focusScope struct {
+	root    Component
+	restore View
+}
+
+type focusManager struct {
+	window        Window
+	focused       View
+	style         ViewStyle
+	bindings      FocusBindings
+	scopes        []focusScope
+	wrap          bool
+	followPointer bool
+	lastPointer   *image.Point
+	// the focusable view under the pointer
+	pointerTarget View
+}
+type FocusManager = *focusManager
+
+func newFocusManager(window Window) FocusManager {
+	return &focusManager{
+		window:        window,
+		style:         defaultFocusStyle,
+		bindings:      DefaultFocusBindings,
+		wrap:          true,
+		followPointer: true,
+	}
+}
+
+func (w Window) FocusManager() FocusManager {
+	return w.focus
+}
+
+func (f FocusManager) Focused() View {
+	return f.focused
+}
+
+func (f FocusManager) Focus(view View) {
+	if f.focused == view {
+		return
+	}
+	f.Blur()
+	if view == nil {
+		return
+	}
+	f.focused = view
+	view.focused = true
+	view.focusStyle = &f.style
+	if view.focusHandlers.focus != nil {
+		view.focusHandlers.focus()
+	}
+}
+
+func (f FocusManager) Blur() {
+	var view = f.focused
+	if view == nil {
+		return
+	}
+	f.focused = nil
+	view.focused = false
+	view.focusStyle = nil
+	if view.focusHandlers.blur != nil {
+		view.focusHandlers.blur()
+	}
+}
+
+// SetFocusStyle sets the style merged into the focused view.
+func (f FocusManager) SetFocusStyle(style ViewStyle) {
+	f.style = style
+}
+
+func (f FocusManager) SetBindings(bindings FocusBindings) {
+	f.bindings = bindings
+}
+
+// SetWrap sets whether moving past the last view focuses the view at the other end.
+func (f FocusManager) SetWrap(wrap bool) {
+	f.wrap = wrap
+}
+
+// SetFollowPointer sets whether moving the pointer focuses the focusable view under it.
+func (f FocusManager) SetFollowPointer(follow bool) {
+	f.followPointer = follow
+}
+
+// PushScope keeps the focus inside root until PopScope, e.g. while a modal is open.
+func (f FocusManager) PushScope(root Component) {
+	f.scopes = append(f.scopes, focusScope{root: root, restore: f.focused})
+	if f.focused != nil && !Contains(root, f.focused) {
+		f.Blur()
+	}
+}
+
+// PopScope leaves the last scope and focuses the view that was focused when it was pushed.
+func (f FocusManager) PopScope() {
+	if len(f.scopes) == 0 {
+		return
+	}
+	var scope = f.scopes[len(f.scopes)-1]
+	f.scopes = f.scopes[:len(f.scopes)-1]
+	f.Focus(scope.restore)
+}
+
+// focusables returns the focusable views of the current scope in tab order.
+func (f FocusManager) focusables() []View {
+	var roots = f.window.Components()
+	if len(f.scopes) > 0 {
+		roots = []Component{f.scopes[len(f.scopes)-1].root}
+	}
+	var views = []View{}
+	walkComponents(roots, 0, func(component Component, depth int) {
+		if view, ok := component.(View); ok && view.focusable && !view.disabled {
+			views = append(views, view)
+		}
+	})
+	return views
+}
+
+func (f FocusManager) Next() {
+	f.step(1)
+}
+
+func (f FocusManager) Prev() {
+	f.step(-1)
+}
+
+func (f FocusManager) step(delta int) {
+	var views = f.focusables()
+	if len(views) == 0 {
+		return
+	}
+	var index = indexOfView(views, f.focused)
+	if index < 0 {
+		if delta > 0 {
+			index = len(views) - 1
+		} else {
+			index = 0
+		}
+	}
+	f.Focus(views[(index+delta+len(views))%len(views)])
+}
+
+// Move focuses the nearest focusable view in direction from the focused view.
+func (f FocusManager) Move(direction FocusDirection) {
+	var views = f.focusables()
+	if len(views) == 0 {
+		return
+	}
+	if f.focused == nil || indexOfView(views, f.focused) < 0 {
+		f.Focus(views[0])
+		return
+	}
+	if next := nearestView(f.focused, views, direction, f.wrap); next != nil {
+		f.Focus(next)
+	}
+}
+
+func indexOfView(views []View, view View) int {
+	for i, v := range views {
+		if v == view {
+			return i
+		}
+	}
+	return -1
+}
+
+func directionVector(direction FocusDirection) image.Point {
+	switch direction {
+	case FocusUp:
+		return image.Point{Y: -1}
+	case FocusRight:
+		return image.Point{X: 1}
+	case FocusDown:
+		return image.Point{Y: 1}
+	default:
+		return image.Point{X: -1}
+	}
+}
+
+func areaCenter(area image.Rectangle) image.Point {
+	return image.Point{X: (area.Min.X + area.Max.X) / 2, Y: (area.Min.Y + area.Max.Y) / 2}
+}
+
+// nearestView scores the views by the distance along direction plus twice the distance across it.
+// When nothing lies in direction and wrap is set, the farthest view in the opposite direction is chosen.
+func nearestView(from View, views []View, direction FocusDirection, wrap bool) View {
+	var d = directionVector(direction)
+	var center = areaCenter(from.Area())
+	var best, wrapped View
+	var bestScore, wrappedScore = math.MaxInt, math.MaxInt
+	for _, view := range views {
+		if view == from || view.Area().Empty() {
+			continue
+		}
+		var diff = areaCenter(view.Area()).Sub(center)
+		var along = diff.X*d.X + diff.Y*d.Y
+		var across = diff.X*d.Y - diff.Y*d.X
+		if across < 0 {
+			across = -across
+		}
+		var score = along + 2*across
+		if along > 0 && score < bestScore {
+			best, bestScore = view, score
+		} else if along < 0 && score < wrappedScore {
+			wrapped, wrappedScore = view, score
+		}
+	}
+	if best == nil && wrap {
+		return wrapped
+	}
+	return best
+}
+
+// repeats reports whether a binding held for duration ticks fires this tick.
+func repeats(duration int) bool {
+	return duration == 1 || (duration > focusRepeatDelay && (duration-focusRepeatDelay)%focusRepeatInterval == 0)
+}
+
+func keyFired(keys []ebiten.Key, repeat bool) bool {
+	for _, key := range keys {
+		var duration = inpututil.KeyPressDuration(key)
+		if duration == 1 || (repeat && repeats(duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+func gamepadFired(buttons []ebiten.StandardGamepadButton, repeat bool) bool {
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for _, button := range buttons {
+			if button < 0 || button > ebiten.StandardGamepadButtonMax {
+				continue
+			}
+			var duration = inpututil.StandardGamepadButtonPressDuration(id, button)
+			if duration == 1 || (repeat && repeats(duration)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f FocusManager) update(ctx *updateContext, path []Component) {
+	var views = f.focusables()
+	if f.focused != nil && indexOfView(views, f.focused) < 0 {
+		f.Blur()
+	}
+
+	var target View
+	for i := len(path) - 1; i >= 0 && target == nil; i-- {
+		if view, ok := path[i].(View); ok && indexOfView(views, view) >= 0 {
+			target = view
+		}
+	}
+	// the focus moves when the pointer enters a view, leaving the views keeps the focus set with the keys or a gamepad,
+	// a view editing text keeps the focus until the pointer is pressed somewhere else
+	var capturing = f.focused != nil && len(f.focused.capturedKeys) > 0
+	if f.followPointer && f.lastPointer != nil {
+		if capturing && ctx.justPressed {
+			if target != nil {
+				f.Focus(target)
+			} else {
+				f.Blur()
+			}
+		} else if !capturing && target != nil && target != f.pointerTarget && ctx.pointer != *f.lastPointer {
+			f.Focus(target)
+		}
+	}
+	f.pointerTarget = target
+	f.lastPointer = &ctx.pointer
+
+	var b = f.bindings
+	if f.focused != nil {
+		b = b.withoutKeys(f.focused.capturedKeys)
+	}
+	var shift = ebiten.IsKeyPressed(ebiten.KeyShift)
+	switch {
+	case keyFired(b.Up, true) || gamepadFired(b.GamepadUp, true):
+		f.Move(FocusUp)
+	case keyFired(b.Right, true) || gamepadFired(b.GamepadRight, true):
+		f.Move(FocusRight)
+	case keyFired(b.Down, true) || gamepadFired(b.GamepadDown, true):
+		f.Move(FocusDown)
+	case keyFired(b.Left, true) || gamepadFired(b.GamepadLeft, true):
+		f.Move(FocusLeft)
+	case (keyFired(b.Next, true) && shift) || gamepadFired(b.GamepadPrev, true):
+		f.Prev()
+	case keyFired(b.Next, true) || gamepadFired(b.GamepadNext, true):
+		f.Next()
+	case f.focused != nil && (keyFired(b.Activate, false) || gamepadFired(b.GamepadActivate, false)):
+		var center = areaCenter(f.focused.Area())
+		f.focused.handlers.click.call(PointerEvent{X: center.X, Y: center.Y, Target: f.focused})
+	}
+}
+
+// withoutKeys returns the bindings without the keys, which the focused view handles itself.
+func (b FocusBindings) withoutKeys(keys []ebiten.Key) FocusBindings {
+	if len(keys) == 0 {
+		return b
+	}
+	var filter = func(bound []ebiten.Key) []ebiten.Key {
+		var rest = []ebiten.Key{}
+		for _, key := range bound {
+			if !slices.Contains(keys, key) {
+				rest = append(rest, key)
+			}
+		}
+		return rest
+	}
+	b.Up, b.Right, b.Down, b.Left = filter(b.Up), filter(b.Right), filter(b.Down), filter(b.Left)
+	b.Next, b.Activate = filter(b.Next), filter(b.Activate)
+	return b
+}
+
+func (v View) SetFocusable(focusable bool) {
+	v.focusable = focusable
+}
+func (v View) IsFocusable() bool {
+	return v.focusable
+}
+func (v View) IsFocused() bool {
+	return v.focused
+}
+func (v View) OnFocus(handler func()) {
+	v.focusHandlers.focus = handler
+}
+func (v View) OnBlur(handler func()) {
+	v.focusHandlers.blur = handler
+}
@@ -0,0 +1,167 @@
+package game_ui
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/bitmapfont/v4"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font"
+)
+
+type FontWeight = text.Weight
+
+const (
+	FontWeightThin       FontWeight = text.WeightThin
+	FontWeightExtraLight FontWeight = text.WeightExtraLight
+	FontWeightLight      FontWeight = text.WeightLight
+	FontWeightNormal     FontWeight = text.WeightNormal
+	FontWeightMedium     FontWeight = text.WeightMedium
+	FontWeightSemibold   FontWeight = text.WeightSemibold
+	FontWeightBold       FontWeight = text.WeightBold
+	FontWeightExtraBold  FontWeight = text.WeightExtraBold
+	FontWeightBlack      FontWeight = text.WeightBlack
+)
+
+// fontEntry is a face of the fallback chain, or the weights of a family.
+type fontEntry struct {
+	face   text.Face
+	family []*text.GoTextFaceSource
+}
+
+type fontFaceKey struct {
+	size   float64
+	weight FontWeight
+}
+
+// fontFace is a face resolved for a size and a weight.
+type fontFace struct {
+	face text.Face
+	// applied when the faces cannot be resized
+	scale float64
+	// px the glyphs are moved by
+	dx, dy float64
+}
+
+// TextFont is a chain of faces, a character missing in a face is drawn with the next one.
+type TextFont struct {
+	entries []fontEntry
+	// px, the size of the text when the style does not set one
+	size  float64
+	faces map[fontFaceKey]fontFace
+	// px at size the glyphs are moved by
+	xAdjustment, yAdjustment float64
+}
+
+var defaultTextFont = TextFont{
+	entries: []fontEntry{{face: text.NewGoXFace(bitmapfont.Face)}},
+	size:    12,
+	faces:   map[fontFaceKey]fontFace{},
+}
+
+// NewTextFont creates a font of face with the glyphs moved by the adjustments in px.
+//
+// Deprecated: use NewTextFaces with text/v2 faces.
+func NewTextFont(face font.Face, xAdjustment int, yAdjustment int) TextFont {
+	var textFont = NewTextFaces(text.NewGoXFace(face))
+	textFont.xAdjustment, textFont.yAdjustment = float64(xAdjustment), float64(yAdjustment)
+	return textFont
+}
+
+// NewTextFaces creates a font of faces in fallback order, e.g. a latin font, a CJK font and then bitmapfont.
+// A font of *text.GoTextFace is resized to the Size of a style. A font with other faces is scaled from its
+// own size, the size of the first *text.GoTextFace or else the ascent of the first face.
+func NewTextFaces(faces ...text.Face) TextFont {
+	var font = TextFont{faces: map[fontFaceKey]fontFace{}}
+	for _, face := range faces {
+		font.entries = append(font.entries, fontEntry{face: face})
+		if goText, ok := face.(*text.GoTextFace); ok && font.size == 0 {
+			font.size = goText.Size
+		}
+	}
+	if font.size == 0 && len(faces) > 0 {
+		font.size = faces[0].Metrics().HAscent
+	}
+	return font
+}
+
+// NewFontFamily creates a font of the weights of a family, the Weight of a style picks the nearest one.
+func NewFontFamily(size float64, sources ...*text.GoTextFaceSource) TextFont {
+	return TextFont{
+		entries: []fontEntry{{family: sources}},
+		size:    size,
+		faces:   map[fontFaceKey]fontFace{},
+	}
+}
+
+// WithFallback returns the font followed by the faces of fallbacks.
+func (f TextFont) WithFallback(fallbacks ...TextFont) TextFont {
+	var font = f
+	font.entries = append([]fontEntry{}, f.entries...)
+	font.faces = map[fontFaceKey]fontFace{}
+	for _, fallback := range fallbacks {
+		font.entries = append(font.entries, fallback.entries...)
+	}
+	return font
+}
+
+// nearestWeight returns the source of family whose weight is the closest to weight.
+func nearestWeight(family []*text.GoTextFaceSource, weight FontWeight) *text.GoTextFaceSource {
+	var nearest *text.GoTextFaceSource
+	var distance = math.Inf(1)
+	for _, source := range family {
+		if d := math.Abs(float64(source.Metadata().Weight - weight)); d < distance {
+			nearest, distance = source, d
+		}
+	}
+	return nearest
+}
+
+// resolve returns the face of the font for size px and weight.
+func (f TextFont) resolve(size float64, weight FontWeight) fontFace {
+	if len(f.entries) == 0 {
+		return defaultTextFont.resolve(size, weight)
+	}
+	var key = fontFaceKey{size: size, weight: weight}
+	if face, ok := f.faces[key]; ok {
+		return face
+	}
+	// a chain with a face that cannot be resized is scaled as a whole, so that its faces keep their sizes to each other
+	var resizable = true
+	for _, entry := range f.entries {
+		if _, ok := entry.face.(*text.GoTextFace); len(entry.family) == 0 && !ok {
+			resizable = false
+		}
+	}
+	var faces = []text.Face{}
+	for _, entry := range f.entries {
+		if len(entry.family) > 0 {
+			var familySize = size
+			if !resizable {
+				familySize = f.size
+			}
+			faces = append(faces, &text.GoTextFace{Source: nearestWeight(entry.family, weight), Size: familySize})
+		} else if goText, ok := entry.face.(*text.GoTextFace); ok && resizable {
+			var resized = *goText
+			resized.Size = size
+			faces = append(faces, &resized)
+		} else {
+			faces = append(faces, entry.face)
+		}
+	}
+	var resolved = fontFace{face: faces[0], scale: 1}
+	if len(faces) > 1 {
+		if multi, err := text.NewMultiFace(faces...); err == nil {
+			resolved.face = multi
+		}
+	}
+	if !resizable && f.size > 0 {
+		resolved.scale = size / f.size
+	}
+	if f.size > 0 {
+		resolved.dx, resolved.dy = f.xAdjustment*size/f.size, f.yAdjustment*size/f.size
+	}
+	if f.faces != nil {
+		f.faces[key] = resolved
+	}
+	return resolved
+}
@@ -5,6 +5,7 @@ go 1.24.0
 require (
 	github.com/hajimehoshi/bitmapfont/v4 v4.1.0
 	github.com/hajimehoshi/ebiten/v2 v2.9.7
+	github.com/rivo/uniseg v0.4.7
 	golang.org/x/image v0.34.0
 )
 
@@ -12,6 +13,7 @@ require (
 	github.com/ebitengine/gomobile v0.0.0-20250923094054-ea854a63cce1 // indirect
 	github.com/ebitengine/hideconsole v1.0.0 // indirect
 	github.com/ebitengine/purego v0.9.1 // indirect
+	github.com/go-text/typesetting v0.3.0 // indirect
 	github.com/jezek/xgb v1.2.0 // indirect
 	github.com/pierrec/lz4/v4 v4.1.23 // indirect
 	golang.org/x/sync v0.19.0 // indirect
@@ -0,0 +1,473 @@
+package game_ui
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type gridComponent struct {
+	items       []GridItem
+	style       GridStyle
+	box         layoutBox
+	boxAssigned bool
+	drawnArea   image.Rectangle
+}
+type Grid = *gridComponent
+type GridStyle struct {
+	// track templates, rows beyond the template are sized by their content
+	Columns, Rows      *[]sizeSeg
+	ColumnGap, RowGap  *sizeSeg
+	Width, Height      *sizeSeg
+	PositionHorizontal *PositionType
+	PositionVertical   *PositionType
+	IsFloating         bool
+}
+type GridItem struct {
+	Component Component
+	// 1-based track index, 0 places the item in the next free cell
+	Column, Row int
+	// number of tracks covered, 0 is treated as 1
+	ColumnSpan, RowSpan int
+	// alignment inside the cell, defaults to the alignment of the grid
+	PositionHorizontal *PositionType
+	PositionVertical   *PositionType
+}
+
+type gridCell struct {
+	column, row         int
+	columnSpan, rowSpan int
+}
+
+func mergeGridStyle(target GridStyle, styles []GridStyle) GridStyle {
+	for i := range styles {
+		if styles[i].Columns != nil {
+			target.Columns = styles[i].Columns
+		}
+		if styles[i].Rows != nil {
+			target.Rows = styles[i].Rows
+		}
+		if styles[i].ColumnGap != nil {
+			target.ColumnGap = styles[i].ColumnGap
+		}
+		if styles[i].RowGap != nil {
+			target.RowGap = styles[i].RowGap
+		}
+		if styles[i].Width != nil {
+			target.Width = styles[i].Width
+		}
+		if styles[i].Height != nil {
+			target.Height = styles[i].Height
+		}
+		if styles[i].PositionHorizontal != nil {
+			target.PositionHorizontal = styles[i].PositionHorizontal
+		}
+		if styles[i].PositionVertical != nil {
+			target.PositionVertical = styles[i].PositionVertical
+		}
+		target.IsFloating = target.IsFloating || styles[i].IsFloating
+	}
+	return target
+}
+
+func Tracks(sizes ...*sizeSeg) *[]sizeSeg {
+	var tracks = make([]sizeSeg, len(sizes))
+	for i := range sizes {
+		tracks[i] = *sizes[i]
+	}
+	return &tracks
+}
+
+func NewGrid(items []GridItem, styles ...GridStyle) Grid {
+	var style = mergeGridStyle(GridStyle{}, styles)
+	return &gridComponent{items: items, style: style}
+}
+
+func GridItems(components ...Component) []GridItem {
+	var items = make([]GridItem, len(components))
+	for i := range components {
+		items[i] = GridItem{Component: components[i]}
+	}
+	return items
+}
+
+func spanOf(span int) int {
+	if span < 1 {
+		return 1
+	}
+	return span
+}
+
+// placeItems resolves the cell of every item, explicit positions first and
+// then the remaining items in row-major order.
+func (g Grid) placeItems() ([]gridCell, int, int) {
+	var columns = 1
+	if g.style.Columns != nil && len(*g.style.Columns) > 0 {
+		columns = len(*g.style.Columns)
+	}
+	for _, item := range g.items {
+		if item.Column > 0 && item.Column-1+spanOf(item.ColumnSpan) > columns {
+			columns = item.Column - 1 + spanOf(item.ColumnSpan)
+		}
+	}
+
+	var occupied = map[image.Point]bool{}
+	var fits = func(column, row, columnSpan, rowSpan int) bool {
+		if column+columnSpan > columns {
+			return false
+		}
+		for r := row; r < row+rowSpan; r++ {
+			for c := column; c < column+columnSpan; c++ {
+				if occupied[image.Point{X: c, Y: r}] {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	var occupy = func(cell gridCell) {
+		for r := cell.row; r < cell.row+cell.rowSpan; r++ {
+			for c := cell.column; c < cell.column+cell.columnSpan; c++ {
+				occupied[image.Point{X: c, Y: r}] = true
+			}
+		}
+	}
+
+	var cells = make([]gridCell, len(g.items))
+	var placed = make([]bool, len(g.items))
+	for i, item := range g.items {
+		if item.Column > 0 && item.Row > 0 {
+			cells[i] = gridCell{item.Column - 1, item.Row - 1, spanOf(item.ColumnSpan), spanOf(item.RowSpan)}
+			occupy(cells[i])
+			placed[i] = true
+		}
+	}
+	var cursor = image.Point{}
+	for i, item := range g.items {
+		if placed[i] {
+			continue
+		}
+		var columnSpan, rowSpan = min(spanOf(item.ColumnSpan), columns), spanOf(item.RowSpan)
+		if item.Column > 0 {
+			// fixed column, search the first free row
+			var row = 0
+			for !fits(item.Column-1, row, columnSpan, rowSpan) {
+				row++
+			}
+			cells[i] = gridCell{item.Column - 1, row, columnSpan, rowSpan}
+		} else {
+			var row, column = cursor.Y, cursor.X
+			if item.Row > 0 {
+				row, column = item.Row-1, 0
+			}
+			for !fits(column, row, columnSpan, rowSpan) {
+				column++
+				if column >= columns {
+					column = 0
+					row++
+				}
+			}
+			cells[i] = gridCell{column, row, columnSpan, rowSpan}
+			if item.Row <= 0 {
+				cursor = image.Point{X: column + columnSpan, Y: row}
+			}
+		}
+		occupy(cells[i])
+	}
+
+	var rows = 0
+	if g.style.Rows != nil {
+		rows = len(*g.style.Rows)
+	}
+	for _, cell := range cells {
+		if cell.row+cell.rowSpan > rows {
+			rows = cell.row + cell.rowSpan
+		}
+	}
+	return cells, columns, rows
+}
+
+// resolveTracks sizes the tracks of one axis. available < 0 means the size is decided by the content.
+func resolveTracks(box layoutBox, template *[]sizeSeg, count, gap, available int, spans [][2]int, sizes []int) []int {
+	var tracks = make([]int, count)
+	var fractions = make([]float32, count)
+	var flexible = make([]bool, count)
+	for i := 0; i < count; i++ {
+		if template == nil || i >= len(*template) {
+			flexible[i] = true
+			continue
+		}
+		var track = (*template)[i]
+		if track.t == auto {
+			flexible[i] = true
+			continue
+		}
+		if track.t == fr {
+			fractions[i] = track.v
+			flexible[i] = true
+			continue
+		}
+		tracks[i] = calcSize(box, track)
+	}
+
+	// content size of flexible tracks, single span items first
+	var content = make([]int, count)
+	for i, span := range spans {
+		if span[1] == 1 && flexible[span[0]] && content[span[0]] < sizes[i] {
+			content[span[0]] = sizes[i]
+		}
+	}
+	for i, span := range spans {
+		if span[1] == 1 {
+			continue
+		}
+		var current = gap * (span[1] - 1)
+		var targets = []int{}
+		for t := span[0]; t < span[0]+span[1]; t++ {
+			if flexible[t] {
+				current += content[t]
+				targets = append(targets, t)
+			} else {
+				current += tracks[t]
+			}
+		}
+		if current < sizes[i] && len(targets) > 0 {
+			var extra = sizes[i] - current
+			for j, t := range targets {
+				content[t] += extra / len(targets)
+				if j < extra%len(targets) {
+					content[t]++
+				}
+			}
+		}
+	}
+
+	var used = gap * max(count-1, 0)
+	var totalFraction float32 = 0
+	for i := 0; i < count; i++ {
+		if fractions[i] > 0 {
+			totalFraction += fractions[i]
+			continue
+		}
+		if flexible[i] {
+			tracks[i] = content[i]
+		}
+		used += tracks[i]
+	}
+	if totalFraction == 0 {
+		return tracks
+	}
+
+	var unit float32 = 0
+	if available >= 0 {
+		unit = float32(available-used) / totalFraction
+	} else {
+		for i := 0; i < count; i++ {
+			if fractions[i] > 0 && float32(content[i])/fractions[i] > unit {
+				unit = float32(content[i]) / fractions[i]
+			}
+		}
+	}
+	for i := 0; i < count; i++ {
+		if fractions[i] > 0 {
+			tracks[i] = max(int(unit*fractions[i]), content[i])
+		}
+	}
+	return tracks
+}
+
+func (g Grid) getGaps() (int, int) {
+	var columnGap, rowGap = 0, 0
+	if g.style.ColumnGap != nil {
+		columnGap = calcSize(g.box, *g.style.ColumnGap)
+	}
+	if g.style.RowGap != nil {
+		rowGap = calcSize(g.box, *g.style.RowGap)
+	}
+	return columnGap, rowGap
+}
+
+// definiteTracks reports the tracks whose size does not depend on the content.
+func definiteTracks(template *[]sizeSeg, count, available int) []bool {
+	var definite = make([]bool, count)
+	for i := 0; template != nil && i < count && i < len(*template); i++ {
+		var track = (*template)[i]
+		definite[i] = track.t != auto && (track.t != fr || available >= 0)
+	}
+	return definite
+}
+
+// measureLength is the length an item spanning span is measured against, the spanned tracks when
+// they do not depend on the content and the available length of the grid otherwise.
+func measureLength(definite []bool, tracks []int, gap int, span [2]int, available int) int {
+	for t := span[0]; t < span[0]+span[1]; t++ {
+		if !definite[t] {
+			return available
+		}
+	}
+	return trackSpan(tracks, gap, span[0], span[1])
+}
+
+func (g Grid) layout() ([]gridCell, []int, []int) {
+	var cells, columns, rows = g.placeItems()
+	var columnGap, rowGap = g.getGaps()
+	var width, height = -1, -1
+	if size, ok := calcLength(g.box, g.style.Width, true); ok {
+		width = size
+	}
+	if size, ok := calcLength(g.box, g.style.Height, false); ok {
+		height = size
+	}
+	// the content box offered to the items of content sized tracks
+	var available = g.box.parent
+	if width >= 0 {
+		available.X = width
+	}
+	if height >= 0 {
+		available.Y = height
+	}
+	var setBox = func(i int, parent image.Point) {
+		if boxed, ok := g.items[i].Component.(boxedComponent); ok {
+			boxed.setLayoutBox(layoutBox{screen: g.box.screen, parent: parent, limit: g.box.limit})
+		}
+	}
+
+	var columnSpans = make([][2]int, len(cells))
+	var rowSpans = make([][2]int, len(cells))
+	var widths = make([]int, len(cells))
+	var heights = make([]int, len(cells))
+	var boxes = make([]image.Point, len(cells))
+	for i, cell := range cells {
+		columnSpans[i] = [2]int{cell.column, cell.columnSpan}
+		rowSpans[i] = [2]int{cell.row, cell.rowSpan}
+	}
+	// the sizes of the tracks that do not depend on the content, measured items are sized against them
+	var definiteColumns = definiteTracks(g.style.Columns, columns, width)
+	var definiteRows = definiteTracks(g.style.Rows, rows, height)
+	var fixedColumns = resolveTracks(g.box, g.style.Columns, columns, columnGap, width, columnSpans, widths)
+	var fixedRows = resolveTracks(g.box, g.style.Rows, rows, rowGap, height, rowSpans, heights)
+	for i := range cells {
+		boxes[i] = image.Point{
+			X: measureLength(definiteColumns, fixedColumns, columnGap, columnSpans[i], available.X),
+			Y: measureLength(definiteRows, fixedRows, rowGap, rowSpans[i], available.Y),
+		}
+		setBox(i, boxes[i])
+		var size = g.items[i].Component.GetSize()
+		widths[i], heights[i] = size.X, size.Y
+	}
+	var columnTracks = resolveTracks(g.box, g.style.Columns, columns, columnGap, width, columnSpans, widths)
+	// the rows are measured with the width of the cells, e.g. for wrapped text
+	for i := range cells {
+		var cellWidth = trackSpan(columnTracks, columnGap, columnSpans[i][0], columnSpans[i][1])
+		if cellWidth != boxes[i].X {
+			boxes[i].X = cellWidth
+			setBox(i, boxes[i])
+			heights[i] = g.items[i].Component.GetSize().Y
+		}
+	}
+	var rowTracks = resolveTracks(g.box, g.style.Rows, rows, rowGap, height, rowSpans, heights)
+	for i := range cells {
+		setBox(i, image.Point{
+			X: boxes[i].X,
+			Y: trackSpan(rowTracks, rowGap, rowSpans[i][0], rowSpans[i][1]),
+		})
+	}
+	return cells, columnTracks, rowTracks
+}
+
+func sumTracks(tracks []int, gap int) int {
+	var size = gap * max(len(tracks)-1, 0)
+	for _, track := range tracks {
+		size += track
+	}
+	return size
+}
+
+func trackOffset(tracks []int, gap, index int) int {
+	var offset = 0
+	for i := 0; i < index; i++ {
+		offset += tracks[i] + gap
+	}
+	return offset
+}
+
+// trackSpan is the size of span tracks from index with the gaps between them.
+func trackSpan(tracks []int, gap, index, span int) int {
+	return trackOffset(tracks, gap, index+span) - gap - trackOffset(tracks, gap, index)
+}
+
+func (g Grid) GetSize() image.Point {
+	var _, columnTracks, rowTracks = g.layout()
+	return g.sizeOf(columnTracks, rowTracks)
+}
+
+func (g Grid) sizeOf(columnTracks, rowTracks []int) image.Point {
+	var columnGap, rowGap = g.getGaps()
+	var x, y = sumTracks(columnTracks, columnGap), sumTracks(rowTracks, rowGap)
+	if width, ok := calcLength(g.box, g.style.Width, true); ok {
+		x = max(x, width)
+	}
+	if height, ok := calcLength(g.box, g.style.Height, false); ok {
+		y = max(y, height)
+	}
+	return image.Point{X: x, Y: y}
+}
+
+func (g Grid) setLayoutBox(box layoutBox) {
+	g.box = box
+	g.boxAssigned = true
+}
+
+func (g Grid) Draw(screen *ebiten.Image, x, y int) {
+	// a laid out component keeps the screen of its parent, it may be drawn on a clip layer
+	if !g.boxAssigned {
+		g.box = screenBox(screen)
+	}
+	var cells, columnTracks, rowTracks = g.layout()
+	var size = g.sizeOf(columnTracks, rowTracks)
+	g.drawnArea = image.Rect(x, y, x+size.X, y+size.Y)
+	var columnGap, rowGap = g.getGaps()
+	for i, cell := range cells {
+		var item = g.items[i]
+		var left = trackOffset(columnTracks, columnGap, cell.column)
+		var top = trackOffset(rowTracks, rowGap, cell.row)
+		var width = trackSpan(columnTracks, columnGap, cell.column, cell.columnSpan)
+		var height = trackSpan(rowTracks, rowGap, cell.row, cell.rowSpan)
+
+		var positionH, positionV = g.style.PositionHorizontal, g.style.PositionVertical
+		if item.PositionHorizontal != nil {
+			positionH = item.PositionHorizontal
+		}
+		if item.PositionVertical != nil {
+			positionV = item.PositionVertical
+		}
+		var componentSize = item.Component.GetSize()
+		left += int(positionRate(positionH) * float64(width-componentSize.X))
+		top += int(positionRate(positionV) * float64(height-componentSize.Y))
+		item.Component.Draw(screen, x+left, y+top)
+	}
+}
+
+func (g Grid) IsFloating() bool {
+	return g.style.IsFloating
+}
+
+func (g Grid) Components() []Component {
+	var components = make([]Component, len(g.items))
+	for i := range g.items {
+		components[i] = g.items[i].Component
+	}
+	return components
+}
+
+func (g Grid) Area() image.Rectangle {
+	return g.drawnArea
+}
+
+func (g Grid) Dispose() {
+	for _, item := range g.items {
+		if d, ok := item.Component.(disposer); ok {
+			d.Dispose()
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package game_ui
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type InputDevice = string
+
+const (
+	DeviceKeyboard    InputDevice = "keyboard"
+	DeviceXbox        InputDevice = "xbox"
+	DevicePlayStation InputDevice = "playstation"
+)
+
+var icons = map[InputDevice]map[string]*ebiten.Image{}
+var activeInputDevice = DeviceKeyboard
+
+// iconGeneration changes with the icons and the active device so that texts lay out their icons again.
+var iconGeneration = 0
+
+// RegisterIcon sets the image of the icon named name for device, e.g. "confirm" to the A button of DeviceXbox.
+func RegisterIcon(device InputDevice, name string, img *ebiten.Image) {
+	if icons[device] == nil {
+		icons[device] = map[string]*ebiten.Image{}
+	}
+	icons[device][name] = img
+	iconGeneration++
+}
+
+// SetInputDevice switches the icons of every Text to the ones of device.
+func SetInputDevice(device InputDevice) {
+	if activeInputDevice == device {
+		return
+	}
+	activeInputDevice = device
+	iconGeneration++
+}
+
+func ActiveInputDevice() InputDevice {
+	return activeInputDevice
+}
+
+// lookupIcon returns the icon of the active device, or the keyboard one when the device does not have it.
+func lookupIcon(name string) *ebiten.Image {
+	if img, ok := icons[activeInputDevice][name]; ok {
+		return img
+	}
+	return icons[DeviceKeyboard][name]
+}
+
+// GamepadDevice guesses the kind of the gamepad from its vendor, pads other than PlayStation ones are DeviceXbox.
+func GamepadDevice(id ebiten.GamepadID) InputDevice {
+	var guid = ebiten.GamepadSDLID(id)
+	// the vendor id is stored little endian from the 9th hex digit of the SDL GUID
+	if len(guid) >= 12 && strings.EqualFold(guid[8:12], "4c05") {
+		return DevicePlayStation
+	}
+	var name = strings.ToLower(ebiten.GamepadName(id))
+	if strings.Contains(name, "playstation") || strings.Contains(name, "dualshock") || strings.Contains(name, "dualsense") {
+		return DevicePlayStation
+	}
+	return DeviceXbox
+}
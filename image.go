@@ -0,0 +1,276 @@
+package game_ui
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type FitType = string
+
+const (
+	// scale to fit inside the box, keeping the aspect ratio
+	FitContain FitType = "contain"
+	// scale to cover the box, keeping the aspect ratio, the rest is cropped
+	FitCover FitType = "cover"
+	// scale to the box
+	FitStretch FitType = "stretch"
+	// keep the size of the image, the rest is cropped
+	FitNone FitType = "none"
+)
+
+type imageComponent struct {
+	image       *ebiten.Image
+	style       ImageStyle
+	box         layoutBox
+	boxAssigned bool
+	drawnArea   image.Rectangle
+}
+type Image = *imageComponent
+type ImageStyle struct {
+	// the size of the image when not set, the other side keeps the aspect ratio when only one is set
+	Width, Height      *sizeSeg
+	Fit                *FitType
+	Tint               *color.Color
+	PositionHorizontal *PositionType
+	PositionVertical   *PositionType
+	IsFloating         bool
+}
+
+func mergeImageStyle(target ImageStyle, styles []ImageStyle) ImageStyle {
+	for i := range styles {
+		if styles[i].Width != nil {
+			target.Width = styles[i].Width
+		}
+		if styles[i].Height != nil {
+			target.Height = styles[i].Height
+		}
+		if styles[i].Fit != nil {
+			target.Fit = styles[i].Fit
+		}
+		if styles[i].Tint != nil {
+			target.Tint = styles[i].Tint
+		}
+		if styles[i].PositionHorizontal != nil {
+			target.PositionHorizontal = styles[i].PositionHorizontal
+		}
+		if styles[i].PositionVertical != nil {
+			target.PositionVertical = styles[i].PositionVertical
+		}
+		target.IsFloating = target.IsFloating || styles[i].IsFloating
+	}
+	return target
+}
+
+func NewImage(img *ebiten.Image, styles ...ImageStyle) Image {
+	var style = mergeImageStyle(ImageStyle{}, styles)
+	return &imageComponent{image: img, style: style}
+}
+
+func (i Image) SetImage(img *ebiten.Image) {
+	i.image = img
+}
+
+func (i Image) imageSize() image.Point {
+	if i.image == nil {
+		return image.Point{}
+	}
+	return i.image.Bounds().Size()
+}
+
+func (i Image) GetSize() image.Point {
+	var natural = i.imageSize()
+	var width, hasWidth = calcLength(i.box, i.style.Width, true)
+	var height, hasHeight = calcLength(i.box, i.style.Height, false)
+	switch {
+	case hasWidth && hasHeight:
+		return image.Point{X: width, Y: height}
+	case hasWidth:
+		if natural.X == 0 {
+			return image.Point{X: width}
+		}
+		return image.Point{X: width, Y: natural.Y * width / natural.X}
+	case hasHeight:
+		if natural.Y == 0 {
+			return image.Point{Y: height}
+		}
+		return image.Point{X: natural.X * height / natural.Y, Y: height}
+	}
+	return natural
+}
+
+func (i Image) setLayoutBox(box layoutBox) {
+	i.box = box
+	i.boxAssigned = true
+}
+
+// fitImage returns the part of an image of size src to draw and where to draw it inside area.
+func fitImage(src image.Point, area image.Rectangle, fit FitType, horizontal, vertical *PositionType) (image.Rectangle, image.Rectangle) {
+	var crop = image.Rectangle{Max: src}
+	if src.X == 0 || src.Y == 0 || area.Empty() || fit == FitStretch {
+		return crop, area
+	}
+	var scale = 1.0
+	var sx, sy = float64(area.Dx()) / float64(src.X), float64(area.Dy()) / float64(src.Y)
+	switch fit {
+	case FitContain:
+		scale = math.Min(sx, sy)
+	case FitCover:
+		scale = math.Max(sx, sy)
+	}
+	var rateX, rateY = positionRate(horizontal), positionRate(vertical)
+	if horizontal == nil {
+		rateX = 0.5
+	}
+	if vertical == nil {
+		rateY = 0.5
+	}
+	// crop what does not fit in area
+	var visible = image.Point{
+		X: min(src.X, int(math.Round(float64(area.Dx())/scale))),
+		Y: min(src.Y, int(math.Round(float64(area.Dy())/scale))),
+	}
+	crop.Min = image.Point{X: int(rateX * float64(src.X-visible.X)), Y: int(rateY * float64(src.Y-visible.Y))}
+	crop.Max = crop.Min.Add(visible)
+	var size = image.Point{X: int(math.Round(float64(visible.X) * scale)), Y: int(math.Round(float64(visible.Y) * scale))}
+	var origin = area.Min.Add(image.Point{X: int(rateX * float64(area.Dx()-size.X)), Y: int(rateY * float64(area.Dy()-size.Y))})
+	return crop, image.Rectangle{Min: origin, Max: origin.Add(size)}
+}
+
+// drawImageTo draws the crop of src scaled to dst on screen.
+func drawImageTo(screen, src *ebiten.Image, crop, dst image.Rectangle, tint *color.Color) {
+	if crop.Empty() || dst.Empty() {
+		return
+	}
+	var bounds = src.Bounds()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(dst.Dx())/float64(crop.Dx()), float64(dst.Dy())/float64(crop.Dy()))
+	op.GeoM.Translate(float64(dst.Min.X), float64(dst.Min.Y))
+	if tint != nil {
+		op.ColorScale.ScaleWithColor(*tint)
+	}
+	screen.DrawImage(src.SubImage(crop.Add(bounds.Min)).(*ebiten.Image), op)
+}
+
+func (i Image) Draw(screen *ebiten.Image, x, y int) {
+	// a laid out component keeps the screen of its parent, it may be drawn on a clip layer
+	if !i.boxAssigned {
+		i.box = screenBox(screen)
+	}
+	var size = i.GetSize()
+	i.drawnArea = image.Rect(x, y, x+size.X, y+size.Y)
+	if i.image == nil {
+		return
+	}
+	var fit = FitStretch
+	if i.style.Fit != nil {
+		fit = *i.style.Fit
+	}
+	var crop, dst = fitImage(i.imageSize(), i.drawnArea, fit, i.style.PositionHorizontal, i.style.PositionVertical)
+	drawImageTo(screen, i.image, crop, dst, i.style.Tint)
+}
+
+func (i Image) IsFloating() bool {
+	return i.style.IsFloating
+}
+
+func (i Image) Components() []Component {
+	return []Component{}
+}
+
+func (i Image) Area() image.Rectangle {
+	return i.drawnArea
+}
+
+// BackgroundImage fills the border box of a View over its BackgroundColor and border, clipped by Radius.
+type BackgroundImage struct {
+	Image *ebiten.Image
+	// nine-slice insets of the image in px, top right bottom left.
+	// The corners keep their size, the edges and the center stretch. Zero stretches the whole image.
+	Slice [4]int
+	Tint  *color.Color
+}
+
+type backgroundImageKey struct {
+	image         *ebiten.Image
+	slice         [4]int
+	tint          color.RGBA64
+	width, height int
+	radius        [4]int
+}
+
+type backgroundImageCache struct {
+	key   backgroundImageKey
+	image *ebiten.Image
+	valid bool
+}
+
+// drawNineSlice draws src on dst stretched to the size of dst, keeping the corners given by slice.
+func drawNineSlice(dst, src *ebiten.Image, slice [4]int, tint *color.Color) {
+	var size = dst.Bounds().Size()
+	var srcSize = src.Bounds().Size()
+	var top, right, bottom, left = slice[0], slice[1], slice[2], slice[3]
+	// corners shrink together when the box is smaller than them
+	var scaleX, scaleY = 1.0, 1.0
+	if left+right > size.X {
+		scaleX = float64(size.X) / float64(left+right)
+	}
+	if top+bottom > size.Y {
+		scaleY = float64(size.Y) / float64(top+bottom)
+	}
+	var srcXs = [4]int{0, left, srcSize.X - right, srcSize.X}
+	var srcYs = [4]int{0, top, srcSize.Y - bottom, srcSize.Y}
+	var dstXs = [4]int{0, int(float64(left) * scaleX), size.X - int(float64(right)*scaleX), size.X}
+	var dstYs = [4]int{0, int(float64(top) * scaleY), size.Y - int(float64(bottom)*scaleY), size.Y}
+	for row := range 3 {
+		for column := range 3 {
+			var crop = image.Rect(srcXs[column], srcYs[row], srcXs[column+1], srcYs[row+1])
+			var to = image.Rect(dstXs[column], dstYs[row], dstXs[column+1], dstYs[row+1])
+			drawImageTo(dst, src, crop, to, tint)
+		}
+	}
+}
+
+func (c *backgroundImageCache) render(key backgroundImageKey) *ebiten.Image {
+	if c.valid && c.key == key {
+		return c.image
+	}
+	c.dispose()
+	c.key = key
+	c.valid = true
+	if key.image == nil || key.width <= 0 || key.height <= 0 {
+		return nil
+	}
+	c.image = ebiten.NewImage(key.width, key.height)
+	var tint color.Color = key.tint
+	drawNineSlice(c.image, key.image, key.slice, &tint)
+	if key.radius != [4]int{} {
+		fillRoundedRect(c.image, 0, 0, float32(key.width), float32(key.height), key.radius, color.White, ebiten.BlendDestinationIn)
+	}
+	return c.image
+}
+
+func (c *backgroundImageCache) dispose() {
+	if c.image != nil {
+		c.image.Deallocate()
+		c.image = nil
+	}
+	c.valid = false
+}
+
+func newBackgroundImageKey(background BackgroundImage, width, height int, radius [4]int) backgroundImageKey {
+	var tint color.Color = color.White
+	if background.Tint != nil {
+		tint = *background.Tint
+	}
+	return backgroundImageKey{
+		image:  background.Image,
+		slice:  background.Slice,
+		tint:   colorKey(&[4]color.Color{tint})[0],
+		width:  width,
+		height: height,
+		radius: radius,
+	}
+}
@@ -0,0 +1,96 @@
+package game_ui
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	stickDeadZone = 0.2
+)
+
+// pointerState follows the mouse or the first touch between updates.
+type pointerState struct {
+	position image.Point
+	pressed  bool
+	touchID  ebiten.TouchID
+	touching bool
+}
+
+// updateContext is the input and time of one Window.Update call.
+type updateContext struct {
+	now          int64
+	delta        int64
+	pointer      image.Point
+	pressed      bool
+	justPressed  bool
+	justReleased bool
+	wheel        [2]float64
+	stick        [2]float64
+}
+
+func (p *pointerState) update() (justPressed, justReleased bool) {
+	var wasPressed = p.pressed
+	if p.touching {
+		if inpututil.IsTouchJustReleased(p.touchID) {
+			p.touching = false
+		} else {
+			var x, y = ebiten.TouchPosition(p.touchID)
+			p.position = image.Point{X: x, Y: y}
+		}
+	}
+	if !p.touching {
+		if touchIDs := inpututil.AppendJustPressedTouchIDs(nil); len(touchIDs) > 0 {
+			p.touchID = touchIDs[0]
+			p.touching = true
+			var x, y = ebiten.TouchPosition(p.touchID)
+			p.position = image.Point{X: x, Y: y}
+		}
+	}
+	if p.touching {
+		p.pressed = true
+	} else {
+		var x, y = ebiten.CursorPosition()
+		p.position = image.Point{X: x, Y: y}
+		p.pressed = ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	}
+	return p.pressed && !wasPressed, !p.pressed && wasPressed
+}
+
+func newUpdateContext(pointer *pointerState, now, last int64) *updateContext {
+	var ctx = &updateContext{now: now}
+	if last > 0 && now > last {
+		ctx.delta = now - last
+	}
+	ctx.justPressed, ctx.justReleased = pointer.update()
+	ctx.pointer = pointer.position
+	ctx.pressed = pointer.pressed
+
+	ctx.wheel[0], ctx.wheel[1] = ebiten.Wheel()
+
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		var x = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisRightStickHorizontal)
+		var y = ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisRightStickVertical)
+		if math.Abs(x) > math.Abs(ctx.stick[0]) && math.Abs(x) > stickDeadZone {
+			ctx.stick[0] = x
+		}
+		if math.Abs(y) > math.Abs(ctx.stick[1]) && math.Abs(y) > stickDeadZone {
+			ctx.stick[1] = y
+		}
+	}
+	return ctx
+}
+
+// walkComponents visits the components depth first in drawing order.
+func walkComponents(components []Component, depth int, visit func(component Component, depth int)) {
+	for _, component := range components {
+		visit(component, depth)
+		walkComponents(component.Components(), depth+1, visit)
+	}
+}
@@ -0,0 +1,476 @@
+package game_ui
+
+import (
+	"image"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// KeyboardPage is a set of keys of a VirtualKeyboard, each key types its string and "" leaves a gap.
+type KeyboardPage struct {
+	// label of the key that switches to the page
+	Name string
+	Rows [][]string
+	// adds a key that cycles the character before the caret through its dakuten, handakuten and small forms
+	Variants bool
+}
+
+var (
+	KeyboardQwerty = KeyboardPage{
+		Name: "ABC",
+		Rows: [][]string{
+			strings.Split("1234567890", ""),
+			strings.Split("qwertyuiop", ""),
+			strings.Split("asdfghjkl'", ""),
+			strings.Split("zxcvbnm,.?", ""),
+		},
+	}
+	KeyboardKana = KeyboardPage{
+		Name: "かな",
+		Rows: [][]string{
+			strings.Split("あかさたなはまやらわ", ""),
+			strings.Split("いきしちにひみ、りを", ""),
+			strings.Split("うくすつぬふむゆるん", ""),
+			strings.Split("えけせてねへめ。れー", ""),
+			strings.Split("おこそとのほもよろ！", ""),
+		},
+		Variants: true,
+	}
+	KeyboardNumeric = KeyboardPage{
+		Name: "123",
+		Rows: [][]string{
+			{"1", "2", "3"},
+			{"4", "5", "6"},
+			{"7", "8", "9"},
+			{"-", "0", "."},
+		},
+	}
+)
+
+// kanaVariants are the forms a character cycles through with the variant key.
+var kanaVariants = func() map[rune]rune {
+	var variants = map[rune]rune{}
+	for _, cycle := range strings.Fields("あぁ いぃ うぅゔ えぇ おぉ かが きぎ くぐ けげ こご さざ しじ すず せぜ そぞ ただ ちぢ つっづ てで とど " +
+		"はばぱ ひびぴ ふぶぷ へべぺ ほぼぽ やゃ ゆゅ よょ わゎ") {
+		var runes = []rune(cycle)
+		for i, r := range runes {
+			variants[r] = runes[(i+1)%len(runes)]
+		}
+	}
+	return variants
+}()
+
+// KeyboardBindings are the standard gamepad buttons of the shortcuts of a VirtualKeyboard.
+// The keys themselves are moved between and pressed with the FocusBindings.
+type KeyboardBindings struct {
+	Backspace, Space, Shift, NextPage []ebiten.StandardGamepadButton
+	Submit, Close                     []ebiten.StandardGamepadButton
+}
+
+var DefaultKeyboardBindings = KeyboardBindings{
+	Backspace: []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightLeft},
+	Space:     []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightTop},
+	Shift:     []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonFrontBottomLeft},
+	NextPage:  []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonFrontBottomRight},
+	Submit:    []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonCenterRight},
+	Close:     []ebiten.StandardGamepadButton{ebiten.StandardGamepadButtonRightRight},
+}
+
+type VirtualKeyboardStyle struct {
+	// merged over the default styles
+	Box     *ViewStyle
+	Key     *ViewStyle
+	KeyText *TextStyle
+	// the keys of Shift, Space, Backspace and OK
+	WideKey *ViewStyle
+	Pages   *[]KeyboardPage
+}
+
+func mergeVirtualKeyboardStyle(target VirtualKeyboardStyle, styles []VirtualKeyboardStyle) VirtualKeyboardStyle {
+	for i := range styles {
+		if styles[i].Box != nil {
+			target.Box = Ptr(mergeViewStyle(*target.Box, []ViewStyle{*styles[i].Box}))
+		}
+		if styles[i].Key != nil {
+			target.Key = Ptr(mergeViewStyle(*target.Key, []ViewStyle{*styles[i].Key}))
+		}
+		if styles[i].KeyText != nil {
+			target.KeyText = Ptr(mergeTextStyle(*target.KeyText, []TextStyle{*styles[i].KeyText}))
+		}
+		if styles[i].WideKey != nil {
+			target.WideKey = Ptr(mergeViewStyle(*target.WideKey, []ViewStyle{*styles[i].WideKey}))
+		}
+		if styles[i].Pages != nil {
+			target.Pages = styles[i].Pages
+		}
+	}
+	return target
+}
+
+func getDefaultVirtualKeyboardStyle() VirtualKeyboardStyle {
+	var center = Center
+	var horizontal = Horizontal
+	return VirtualKeyboardStyle{
+		Box: &ViewStyle{
+			Padding:            Size1(Px(6)),
+			Gap:                Px(3),
+			BackgroundColor:    ColorCode1(0x000000cc),
+			Radius:             Radius1(4),
+			PositionHorizontal: &center,
+		},
+		Key: &ViewStyle{
+			Width:              Px(20),
+			Height:             Px(20),
+			Direction:          &horizontal,
+			PositionHorizontal: &center,
+			PositionVertical:   &center,
+			BorderWidth:        Size1(Px(1)),
+			BorderColor:        ColorCode1(0xffffff44),
+			BackgroundColor:    ColorCode1(0x333333cc),
+			Radius:             Radius1(3),
+			Focused:            &ViewStyle{BackgroundColor: ColorCode1(0x5599ccff)},
+			Pressed:            &ViewStyle{BackgroundColor: ColorCode1(0x3377aaff)},
+		},
+		KeyText: &TextStyle{},
+		WideKey: &ViewStyle{Width: Px(43)},
+		Pages:   &[]KeyboardPage{KeyboardQwerty, KeyboardKana, KeyboardNumeric},
+	}
+}
+
+type keyboardAction int
+
+const (
+	keyboardType keyboardAction = iota
+	keyboardShift
+	keyboardSpace
+	keyboardBackspace
+	keyboardNextPage
+	keyboardVariant
+	keyboardSubmit
+)
+
+type keyboardKey struct {
+	view   View
+	text   Text
+	value  string
+	action keyboardAction
+}
+
+type virtualKeyboardComponent struct {
+	view     View
+	target   TextInput
+	style    VirtualKeyboardStyle
+	bindings KeyboardBindings
+	page     int
+	// the next letter is typed upper case
+	shift bool
+	keys  []keyboardKey
+	// set while the keyboard is open
+	focus FocusManager
+	close func()
+}
+
+// VirtualKeyboard is an on-screen keyboard of pages of keys that types into a TextInput, for players with a gamepad.
+type VirtualKeyboard = *virtualKeyboardComponent
+
+func NewVirtualKeyboard(target TextInput, styles ...VirtualKeyboardStyle) VirtualKeyboard {
+	var style = mergeVirtualKeyboardStyle(getDefaultVirtualKeyboardStyle(), styles)
+	var k = &virtualKeyboardComponent{target: target, style: style, bindings: DefaultKeyboardBindings}
+	k.view = NewView([]Component{}, *style.Box)
+	k.build()
+	return k
+}
+
+func (k VirtualKeyboard) SetBindings(bindings KeyboardBindings) {
+	k.bindings = bindings
+}
+
+// SetTarget changes the input the keys type into, nil detaches the keyboard from any input.
+func (k VirtualKeyboard) SetTarget(target TextInput) {
+	if k.focus != nil {
+		k.setTyping(false)
+		k.target = target
+		k.setTyping(true)
+		return
+	}
+	k.target = target
+}
+
+// setTyping shows the caret of the target while the keyboard is open.
+func (k VirtualKeyboard) setTyping(typing bool) {
+	if k.target != nil {
+		k.target.typing = typing
+	}
+}
+
+// OnClose is called when the keyboard is closed, e.g. to remove it from its layer.
+func (k VirtualKeyboard) OnClose(handler func()) {
+	k.close = handler
+}
+
+// Open keeps the focus inside the keyboard and focuses its first key.
+func (k VirtualKeyboard) Open(focus FocusManager) {
+	if k.focus != nil {
+		return
+	}
+	k.focus = focus
+	k.setTyping(true)
+	focus.PushScope(k)
+	if len(k.keys) > 0 {
+		focus.Focus(k.keys[0].view)
+	}
+}
+
+// Close gives the focus back to the view focused before Open.
+func (k VirtualKeyboard) Close() {
+	if k.focus == nil {
+		return
+	}
+	k.focus.PopScope()
+	k.focus = nil
+	k.setTyping(false)
+	if k.close != nil {
+		k.close()
+	}
+}
+
+func (k VirtualKeyboard) IsOpen() bool {
+	return k.focus != nil
+}
+
+func (k VirtualKeyboard) pages() []KeyboardPage {
+	return *k.style.Pages
+}
+
+// hasCase reports whether the keys of page change with shift.
+func hasCase(page KeyboardPage) bool {
+	for _, row := range page.Rows {
+		for _, key := range row {
+			if strings.ToUpper(key) != key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (k VirtualKeyboard) addKey(row *[]Component, label, value string, action keyboardAction, wide bool) {
+	var style = *k.style.Key
+	if wide {
+		style = mergeViewStyle(style, []ViewStyle{*k.style.WideKey})
+	}
+	var key = keyboardKey{text: NewText(label, *k.style.KeyText), value: value, action: action}
+	key.view = NewView([]Component{key.text}, style)
+	key.view.SetFocusable(true)
+	key.view.OnClick(func(event PointerEvent) {
+		k.press(key)
+	})
+	k.keys = append(k.keys, key)
+	*row = append(*row, key.view)
+}
+
+// build lays out the keys of the current page.
+func (k VirtualKeyboard) build() {
+	var pages = k.pages()
+	if len(pages) == 0 {
+		return
+	}
+	var page = pages[k.page]
+	var horizontal = Horizontal
+	var rowStyle = ViewStyle{Direction: &horizontal, Gap: k.style.Box.Gap}
+	k.keys = nil
+	var rows = []Component{}
+	for _, keys := range page.Rows {
+		var row = []Component{}
+		for _, key := range keys {
+			if key == "" {
+				row = append(row, NewView([]Component{}, ViewStyle{Width: k.style.Key.Width, Height: k.style.Key.Height}))
+				continue
+			}
+			k.addKey(&row, key, key, keyboardType, false)
+		}
+		rows = append(rows, NewView(row, rowStyle))
+	}
+	var row = []Component{}
+	if len(pages) > 1 {
+		k.addKey(&row, pages[(k.page+1)%len(pages)].Name, "", keyboardNextPage, true)
+	}
+	if hasCase(page) {
+		k.addKey(&row, "Shift", "", keyboardShift, true)
+	}
+	if page.Variants {
+		k.addKey(&row, "゛゜", "", keyboardVariant, true)
+	}
+	k.addKey(&row, "Space", " ", keyboardSpace, true)
+	k.addKey(&row, "BS", "", keyboardBackspace, true)
+	k.addKey(&row, "OK", "", keyboardSubmit, true)
+	rows = append(rows, NewView(row, rowStyle))
+	k.view.components = rows
+	k.relabel()
+}
+
+// relabel shows the keys in the case of shift.
+func (k VirtualKeyboard) relabel() {
+	for _, key := range k.keys {
+		if key.action == keyboardType {
+			key.text.ChangeText(k.caseOf(key.value))
+		}
+	}
+}
+
+func (k VirtualKeyboard) caseOf(value string) string {
+	if k.shift {
+		return strings.ToUpper(value)
+	}
+	return value
+}
+
+func (k VirtualKeyboard) setShift(shift bool) {
+	if k.shift != shift {
+		k.shift = shift
+		k.relabel()
+	}
+}
+
+// nextPage switches to the next page and focuses its key that switches pages, so that it can be pressed again.
+func (k VirtualKeyboard) nextPage() {
+	k.page = (k.page + 1) % len(k.pages())
+	k.shift = false
+	k.build()
+	if k.focus == nil {
+		return
+	}
+	for _, key := range k.keys {
+		if key.action == keyboardNextPage {
+			k.focus.Focus(key.view)
+		}
+	}
+}
+
+// variant cycles the character before the caret through its forms.
+func (k VirtualKeyboard) variant() {
+	var start, end = k.target.Selection()
+	if start != end {
+		return
+	}
+	var r, size = utf8.DecodeLastRuneInString(k.target.Value()[:start])
+	if next, ok := kanaVariants[r]; ok {
+		k.target.SetSelection(start-size, start)
+		k.target.Insert(string(next))
+	}
+}
+
+func (k VirtualKeyboard) press(key keyboardKey) {
+	// without a target the keys only change the keyboard
+	if k.target == nil && key.action != keyboardShift && key.action != keyboardNextPage && key.action != keyboardSubmit {
+		return
+	}
+	switch key.action {
+	case keyboardType:
+		k.target.Insert(k.caseOf(key.value))
+		k.setShift(false)
+	case keyboardShift:
+		k.setShift(!k.shift)
+	case keyboardSpace:
+		k.target.Insert(" ")
+	case keyboardBackspace:
+		k.target.Backspace()
+	case keyboardNextPage:
+		k.nextPage()
+	case keyboardVariant:
+		k.variant()
+	case keyboardSubmit:
+		if k.target != nil {
+			k.target.Submit()
+		}
+		k.Close()
+	}
+}
+
+// update presses the shortcuts of the bindings while the keyboard is open.
+func (k VirtualKeyboard) update() {
+	if k.focus == nil {
+		return
+	}
+	var b = k.bindings
+	switch {
+	case gamepadFired(b.Backspace, true):
+		k.press(keyboardKey{action: keyboardBackspace})
+	case gamepadFired(b.Space, true):
+		k.press(keyboardKey{action: keyboardSpace})
+	case gamepadFired(b.Shift, false):
+		if hasCase(k.pages()[k.page]) {
+			k.press(keyboardKey{action: keyboardShift})
+		}
+	case gamepadFired(b.NextPage, false) && len(k.pages()) > 1:
+		k.press(keyboardKey{action: keyboardNextPage})
+	case gamepadFired(b.Submit, false):
+		k.press(keyboardKey{action: keyboardSubmit})
+	case gamepadFired(b.Close, false):
+		k.Close()
+	}
+}
+
+// updateKeyboards passes the gamepad to the open virtual keyboards under components.
+func updateKeyboards(components []Component) {
+	var keyboards = []VirtualKeyboard{}
+	walkComponents(components, 0, func(component Component, depth int) {
+		if keyboard, ok := component.(VirtualKeyboard); ok {
+			keyboards = append(keyboards, keyboard)
+		}
+	})
+	// a keyboard may rebuild its keys or be closed
+	for _, keyboard := range keyboards {
+		keyboard.update()
+	}
+}
+
+func (k VirtualKeyboard) GetSize() image.Point {
+	return k.view.GetSize()
+}
+
+func (k VirtualKeyboard) Draw(screen *ebiten.Image, x, y int) {
+	k.view.Draw(screen, x, y)
+}
+
+func (k VirtualKeyboard) IsFloating() bool {
+	return k.view.IsFloating()
+}
+
+func (k VirtualKeyboard) Components() []Component {
+	return []Component{k.view}
+}
+
+func (k VirtualKeyboard) Area() image.Rectangle {
+	return k.view.Area()
+}
+
+func (k VirtualKeyboard) setLayoutBox(box layoutBox) {
+	k.view.setLayoutBox(box)
+}
+
+func (k VirtualKeyboard) measure() image.Point {
+	return k.view.measure()
+}
+
+func (k VirtualKeyboard) flexFactors() (float32, float32) {
+	return k.view.flexFactors()
+}
+
+func (k VirtualKeyboard) setFixedSize(size *image.Point) {
+	k.view.setFixedSize(size)
+}
+
+func (k VirtualKeyboard) fillsAxis(horizontal bool) bool {
+	return k.view.fillsAxis(horizontal)
+}
+
+func (k VirtualKeyboard) positionStyle() ([4]*sizeSeg, AnchorType, int) {
+	return k.view.positionStyle()
+}
+
+func (k VirtualKeyboard) Dispose() {
+	k.view.Dispose()
+}
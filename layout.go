@@ -0,0 +1,138 @@
+package game_ui
+
+import (
+	"image"
+)
+
+// flexItem is implemented by components whose size can be decided by the parent layout.
+type flexItem interface {
+	measure() image.Point
+	flexFactors() (grow, shrink float32)
+	setFixedSize(size *image.Point)
+}
+
+// fillItem is implemented by components that can fill the space offered by the parent.
+type fillItem interface {
+	fillsAxis(horizontal bool) bool
+}
+
+func measureComponent(component Component) image.Point {
+	if item, ok := component.(flexItem); ok {
+		return item.measure()
+	}
+	return component.GetSize()
+}
+
+func isVertical(style ViewStyle) bool {
+	return style.Direction == nil || *style.Direction == Vertical
+}
+
+func mainAxis(point image.Point, vertical bool) int {
+	if vertical {
+		return point.Y
+	}
+	return point.X
+}
+
+func crossAxis(point image.Point, vertical bool) int {
+	if vertical {
+		return point.X
+	}
+	return point.Y
+}
+
+func withMainAxis(point image.Point, vertical bool, value int) image.Point {
+	if vertical {
+		point.Y = value
+	} else {
+		point.X = value
+	}
+	return point
+}
+
+func positionRate(position *PositionType) float64 {
+	if position != nil {
+		if *position == Center {
+			return 0.5
+		} else if *position == Last {
+			return 1
+		}
+	}
+	return 0
+}
+
+// distributeSpace returns the offset before the first child and the extra space between children.
+func distributeSpace(position *PositionType, free, count int) (int, int) {
+	if position == nil || count == 0 {
+		return 0, 0
+	}
+	if free < 0 {
+		switch *position {
+		case SpaceBetween, SpaceAround, SpaceEvenly:
+			return 0, 0
+		}
+	}
+	switch *position {
+	case SpaceBetween:
+		if count == 1 {
+			return 0, 0
+		}
+		return 0, free / (count - 1)
+	case SpaceAround:
+		return free / count / 2, free / count
+	case SpaceEvenly:
+		return free / (count + 1), free / (count + 1)
+	}
+	return int(positionRate(position) * float64(free)), 0
+}
+
+// flexChildren grows or shrinks sizes along the main axis to consume free space
+// and returns the space left over.
+func flexChildren(components []Component, sizes []image.Point, vertical bool, free int) int {
+	var weights = make([]float32, len(components))
+	var total float32 = 0
+	for i, component := range components {
+		item, ok := component.(flexItem)
+		if !ok || component.IsFloating() {
+			continue
+		}
+		var grow, shrink = item.flexFactors()
+		if free > 0 {
+			weights[i] = grow
+		} else if free < 0 {
+			weights[i] = shrink * float32(mainAxis(sizes[i], vertical))
+		}
+		total += weights[i]
+	}
+
+	var used = 0
+	if total > 0 {
+		var acc float32 = 0
+		for i := range components {
+			if weights[i] <= 0 {
+				continue
+			}
+			acc += weights[i]
+			var share = int(float32(free)*acc/total) - used
+			var value = mainAxis(sizes[i], vertical) + share
+			if value < 0 {
+				share -= value
+				value = 0
+			}
+			sizes[i] = withMainAxis(sizes[i], vertical, value)
+			used += share
+		}
+	}
+
+	for i, component := range components {
+		if item, ok := component.(flexItem); ok {
+			if weights[i] > 0 {
+				var size = sizes[i]
+				item.setFixedSize(&size)
+			} else {
+				item.setFixedSize(nil)
+			}
+		}
+	}
+	return free - used
+}
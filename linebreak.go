@@ -0,0 +1,286 @@
+package game_ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/rivo/uniseg"
+)
+
+// HyphenateFunc returns the byte offsets in word where it may be split with a hyphen, e.g. from a dictionary.
+type HyphenateFunc func(word string) []int
+
+// KinsokuHead are the characters that do not start a line, KinsokuTail the ones that do not end a line.
+// They are applied over the Unicode line breaking rules.
+var (
+	KinsokuHead = ")]}）］｝〕〉》」』】〙〗〟’”»、。，．,.・：；:;？！?!‼⁇⁈⁉゛゜ヽヾゝゞ々〻ー…‥ぁぃぅぇぉっゃゅょゎゕゖァィゥェォッャュョヮヵヶㇰㇱㇲㇳㇴㇵㇶㇷㇸㇹㇺㇻㇼㇽㇾㇿ"
+	KinsokuTail = "([{（［｛〔〈《「『【〘〖〝‘“«"
+)
+
+const (
+	breakNone = iota
+	breakAllowed
+	breakRequired
+)
+
+// textItem is a grapheme cluster of a span, or an inline image.
+type textItem struct {
+	glyph      textGlyph
+	breakAfter int
+	space      bool
+	newline    bool
+}
+
+func isNewline(cluster string) bool {
+	switch cluster {
+	case "\n", "\r", "\r\n", "\u0085", "\u2028", "\u2029":
+		return true
+	}
+	return false
+}
+
+// measure returns the advance of cluster, kerned after the cluster prev.
+func (r textRun) measure(cluster, prev string) float64 {
+	if prev == "" {
+		return text.Advance(cluster, r.face) * r.scale
+	}
+	return (text.Advance(prev+cluster, r.face) - text.Advance(prev, r.face)) * r.scale
+}
+
+// lineBreaks returns the break after each byte of str by the Unicode line breaking rules (UAX #14) and kinsoku.
+func lineBreaks(str string) []int {
+	var breaks = make([]int, len(str)+1)
+	var state = -1
+	for pos, rest := 0, str; len(rest) > 0; {
+		var cluster string
+		var boundaries int
+		cluster, rest, boundaries, state = uniseg.StepString(rest, state)
+		pos += len(cluster)
+		switch boundaries & uniseg.MaskLine {
+		case uniseg.LineCanBreak:
+			breaks[pos] = breakAllowed
+		case uniseg.LineMustBreak:
+			breaks[pos] = breakRequired
+		}
+	}
+	for pos := range breaks {
+		if breaks[pos] != breakAllowed || pos == len(str) {
+			continue
+		}
+		var prev, _ = utf8.DecodeLastRuneInString(str[:pos])
+		var next, _ = utf8.DecodeRuneInString(str[pos:])
+		if strings.ContainsRune(KinsokuTail, prev) || strings.ContainsRune(KinsokuHead, next) {
+			breaks[pos] = breakNone
+		}
+	}
+	return breaks
+}
+
+func spanImage(span TextSpan) *ebiten.Image {
+	if span.Image != nil || span.Icon == "" {
+		return span.Image
+	}
+	return lookupIcon(span.Icon)
+}
+
+// collectTextItems splits spans into grapheme clusters with the line breaks between them.
+func collectTextItems(spans []TextSpan, runs []textRun) []textItem {
+	// the text of every span, an image is an object replacement character
+	var full = strings.Builder{}
+	var starts = make([]int, len(spans))
+	var images = make([]*ebiten.Image, len(spans))
+	for i, span := range spans {
+		starts[i] = full.Len()
+		if images[i] = spanImage(span); images[i] != nil {
+			full.WriteString("\uFFFC")
+		} else if span.Image == nil && span.Icon == "" {
+			full.WriteString(span.Text)
+		}
+	}
+	var breaks = lineBreaks(full.String())
+
+	var items = []textItem{}
+	for i, span := range spans {
+		var run = runs[i]
+		if img := images[i]; img != nil {
+			var size = img.Bounds().Size()
+			if size.X > 0 && size.Y > 0 {
+				var glyph = textGlyph{image: img, run: i, advance: run.size * float64(size.X) / float64(size.Y), height: run.size}
+				glyph.offset = starts[i]
+				items = append(items, textItem{glyph: glyph, breakAfter: breaks[starts[i]+len("\uFFFC")]})
+			}
+			continue
+		}
+		if span.Icon != "" {
+			continue
+		}
+		var prev = ""
+		var state = -1
+		for pos, rest := starts[i], span.Text; len(rest) > 0; {
+			var cluster string
+			cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+			pos += len(cluster)
+			var item = textItem{glyph: textGlyph{text: cluster, run: i, offset: pos - len(cluster)}, breakAfter: breaks[pos]}
+			var first, _ = utf8.DecodeRuneInString(cluster)
+			switch {
+			case isNewline(cluster):
+				item.newline = true
+				prev = ""
+			case cluster == "\u00ad":
+				item.glyph.text = ""
+				item.glyph.hyphen = run.measure("-", "")
+			default:
+				item.glyph.advance = run.measure(cluster, prev)
+				item.space = unicode.IsSpace(first)
+				prev = cluster
+			}
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// lineBreaker fills lines with the items between two break opportunities, a word in most languages.
+// A word that does not fit is hyphenated when possible, and a word wider than a line is broken between clusters.
+type lineBreaker struct {
+	layout    *textLayout
+	line      textLine
+	maxWidth  float64
+	hyphenate HyphenateFunc
+}
+
+func (b *lineBreaker) run(item textItem) textRun {
+	return b.layout.runs[item.glyph.run]
+}
+
+func (b *lineBreaker) add(items []textItem) {
+	for _, item := range items {
+		b.line.add(item.glyph, b.run(item), item.space)
+	}
+}
+
+// newLine starts a line with the height of next, wrapped tells that the text goes on in the new line.
+func (b *lineBreaker) newLine(next textRun, wrapped bool) {
+	if wrapped {
+		// the soft hyphen the line ends with is drawn
+		if last := len(b.line.glyphs) - 1; last >= 0 && b.line.glyphs[last].hyphen > 0 {
+			var glyph = &b.line.glyphs[last]
+			glyph.text = "-"
+			glyph.advance = glyph.hyphen
+			b.line.content = glyph.x + glyph.advance
+		}
+		b.line.width = b.line.content
+	}
+	b.line.wrapped = wrapped
+	b.layout.lines = append(b.layout.lines, b.line)
+	b.line = textLine{ascent: next.ascent, descent: next.descent}
+}
+
+// wrap starts a line at next in the middle of the text.
+func (b *lineBreaker) wrap(next textItem) {
+	b.newLine(b.run(next), true)
+	b.line.start = next.glyph.offset
+}
+
+// itemsWidth returns the width of items without the spaces they end with.
+func itemsWidth(items []textItem) float64 {
+	var width, content = 0.0, 0.0
+	for _, item := range items {
+		width += item.glyph.advance
+		if !item.space {
+			content = width + item.glyph.hyphen
+		}
+	}
+	return content
+}
+
+func (b *lineBreaker) breakLines(items []textItem) {
+	for start := 0; start < len(items); {
+		var end = start
+		for end < len(items)-1 && items[end].breakAfter == breakNone && !items[end+1].newline {
+			end++
+		}
+		var word = items[start : end+1]
+		if last := word[len(word)-1]; last.newline {
+			b.place(word[:len(word)-1])
+			var next = b.run(last)
+			if end+1 < len(items) {
+				next = b.run(items[end+1])
+			}
+			b.newLine(next, false)
+			b.line.start = last.glyph.offset + len(last.glyph.text)
+		} else {
+			b.place(word)
+		}
+		start = end + 1
+	}
+	b.layout.lines = append(b.layout.lines, b.line)
+}
+
+func (b *lineBreaker) place(word []textItem) {
+	for len(word) > 0 {
+		var available = b.maxWidth - b.line.end
+		if itemsWidth(word) <= available {
+			b.add(word)
+			return
+		}
+		if n, ok := b.hyphenationPoint(word, available); ok {
+			b.add(word[:n])
+			var last = word[n-1]
+			var run = b.run(last)
+			var hyphen = run.measure("-", "")
+			b.line.add(textGlyph{text: "-", run: last.glyph.run, index: last.glyph.index, offset: word[n].glyph.offset, advance: hyphen, hyphen: hyphen}, run, false)
+			word = word[n:]
+			b.wrap(word[0])
+			continue
+		}
+		if len(b.line.glyphs) > 0 {
+			b.wrap(word[0])
+			continue
+		}
+		// the word is wider than a line
+		var n = 1
+		for width := word[0].glyph.advance; n < len(word) && width+word[n].glyph.advance <= b.maxWidth; n++ {
+			width += word[n].glyph.advance
+		}
+		b.add(word[:n])
+		word = word[n:]
+		if len(word) > 0 {
+			b.wrap(word[0])
+		}
+	}
+}
+
+// hyphenationPoint returns the number of items of word put before a hyphen that fits in available px.
+func (b *lineBreaker) hyphenationPoint(word []textItem, available float64) (int, bool) {
+	if b.hyphenate == nil {
+		return 0, false
+	}
+	// clusters that can be split at, by their byte offset in the word
+	var str = strings.Builder{}
+	var boundaries = map[int]int{}
+	for i, item := range word {
+		if item.glyph.image != nil || item.space {
+			break
+		}
+		str.WriteString(item.glyph.text)
+		boundaries[str.Len()] = i + 1
+	}
+	var offsets = b.hyphenate(str.String())
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	for _, offset := range offsets {
+		var n, ok = boundaries[offset]
+		if !ok || n >= len(word) {
+			continue
+		}
+		var run = b.run(word[n-1])
+		if itemsWidth(word[:n])+run.measure("-", "") <= available {
+			return n, true
+		}
+	}
+	return 0, false
+}
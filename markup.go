@@ -0,0 +1,177 @@
+package game_ui
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+var markupFonts = map[string]*TextFont{}
+
+// RegisterFont names font for the font tag of ParseMarkup.
+func RegisterFont(name string, font TextFont) {
+	markupFonts[name] = &font
+}
+
+// markupTags parse the value of an opening tag into the style of its span.
+var markupTags = map[string]func(value string) (SpanStyle, bool){
+	"color": func(value string) (SpanStyle, bool) {
+		var c, ok = parseColorCode(value)
+		return SpanStyle{Color: c}, ok
+	},
+	"font": func(value string) (SpanStyle, bool) {
+		var font, ok = markupFonts[value]
+		return SpanStyle{Font: font}, ok
+	},
+	"size": func(value string) (SpanStyle, bool) {
+		var size, err = strconv.ParseFloat(value, 64)
+		return SpanStyle{Size: &size}, err == nil && size > 0
+	},
+	"weight": func(value string) (SpanStyle, bool) {
+		var weight, err = strconv.ParseFloat(value, 32)
+		return SpanStyle{Weight: Ptr(FontWeight(weight))}, err == nil && weight > 0
+	},
+	"b": func(value string) (SpanStyle, bool) {
+		return SpanStyle{Weight: Ptr(FontWeightBold)}, value == ""
+	},
+	"speed": func(value string) (SpanStyle, bool) {
+		var speed, err = strconv.ParseFloat(value, 64)
+		return SpanStyle{Speed: &speed}, err == nil && speed > 0
+	},
+	"wave": func(value string) (SpanStyle, bool) {
+		return SpanStyle{Effect: Ptr(EffectWave)}, value == ""
+	},
+	"shake": func(value string) (SpanStyle, bool) {
+		return SpanStyle{Effect: Ptr(EffectShake)}, value == ""
+	},
+	"rainbow": func(value string) (SpanStyle, bool) {
+		return SpanStyle{Effect: Ptr(EffectRainbow)}, value == ""
+	},
+}
+
+// parseColorCode parses #rgb, #rrggbb or #rrggbbaa.
+func parseColorCode(value string) (*color.Color, bool) {
+	var hex, ok = strings.CutPrefix(value, "#")
+	if !ok {
+		return nil, false
+	}
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	if len(hex) != 8 {
+		return nil, false
+	}
+	var code, err = strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, false
+	}
+	return Color(uint32(code)), true
+}
+
+type markupTag struct {
+	name  string
+	style SpanStyle
+}
+
+// ParseMarkup builds the spans of a Text from BBCode-like markup:
+//
+//	Press [color=#ff0000]start[/color] to [size=24]begin[/size]
+//
+// The tags are [color=#rrggbb] (or #rgb, #rrggbbaa), [size=px], [b], [weight=100-900] and [font=name] with a font given to RegisterFont.
+// [speed=2] changes the speed of the reveal of the Text and [wave], [shake] and [rainbow] animate the glyphs.
+// [icon=name] places the icon given to RegisterIcon, [pause=msec] and [marker=name] are waited and reported by the reveal, they have no closing tag.
+// Tags may overlap, [[ writes a [ and a tag that cannot be parsed is written as it is.
+func ParseMarkup(markup string) []TextSpan {
+	var spans = []TextSpan{}
+	var tags = []markupTag{}
+	var str = strings.Builder{}
+	var currentStyle = func() SpanStyle {
+		var style = SpanStyle{}
+		for _, tag := range tags {
+			style = mergeSpanStyle(style, []SpanStyle{tag.style})
+		}
+		return style
+	}
+	var flush = func() {
+		if str.Len() == 0 {
+			return
+		}
+		spans = append(spans, TextSpan{Text: str.String(), Style: currentStyle()})
+		str.Reset()
+	}
+	for len(markup) > 0 {
+		var i = strings.IndexByte(markup, '[')
+		if i < 0 {
+			str.WriteString(markup)
+			break
+		}
+		str.WriteString(markup[:i])
+		markup = markup[i:]
+		if strings.HasPrefix(markup, "[[") {
+			str.WriteByte('[')
+			markup = markup[2:]
+			continue
+		}
+		var end = strings.IndexByte(markup, ']')
+		if end < 0 {
+			str.WriteString(markup)
+			break
+		}
+		var tag = markup[1:end]
+		if name, ok := strings.CutPrefix(tag, "/"); ok {
+			if index := lastMarkupTag(tags, name); index >= 0 {
+				flush()
+				tags = append(tags[:index:index], tags[index+1:]...)
+			} else {
+				str.WriteString(markup[:end+1])
+			}
+		} else if icon, ok := strings.CutPrefix(tag, "icon="); ok && icon != "" {
+			flush()
+			spans = append(spans, TextSpan{Icon: icon, Style: currentStyle()})
+		} else if marker, ok := strings.CutPrefix(tag, "marker="); ok && marker != "" {
+			flush()
+			spans = append(spans, TextSpan{Marker: marker})
+		} else if pause, ok := parseMarkupPause(tag); ok {
+			flush()
+			spans = append(spans, TextSpan{Pause: pause})
+		} else {
+			var name, value, _ = strings.Cut(tag, "=")
+			var parse, ok = markupTags[name]
+			var style SpanStyle
+			if ok {
+				style, ok = parse(value)
+			}
+			if ok {
+				flush()
+				tags = append(tags, markupTag{name: name, style: style})
+			} else {
+				str.WriteString(markup[:end+1])
+			}
+		}
+		markup = markup[end+1:]
+	}
+	flush()
+	return spans
+}
+
+// parseMarkupPause parses the msec of a pause tag.
+func parseMarkupPause(tag string) (int64, bool) {
+	var value, ok = strings.CutPrefix(tag, "pause=")
+	if !ok {
+		return 0, false
+	}
+	var pause, err = strconv.ParseInt(value, 10, 64)
+	return pause, err == nil && pause > 0
+}
+
+func lastMarkupTag(tags []markupTag, name string) int {
+	for i := len(tags) - 1; i >= 0; i-- {
+		if tags[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
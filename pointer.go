@@ -0,0 +1,238 @@
+package game_ui
+
+import (
+	"image"
+)
+
+type PointerEvent struct {
+	X, Y int
+	// Target is the top-most component under the pointer
+	Target Component
+}
+type PointerHandler func(event PointerEvent)
+
+type pointerHandlers struct {
+	enter, leave, down, up, click PointerHandler
+}
+
+// hitTester is implemented by components whose shape is not their whole Area.
+type hitTester interface {
+	containsPoint(point image.Point) bool
+}
+
+// transformedComponent is implemented by components that may be drawn scaled or rotated.
+// Their children and themselves are hit-tested in the coordinates before the transform.
+type transformedComponent interface {
+	untransformPoint(point image.Point) image.Point
+}
+
+// orderedComponent is implemented by components that draw their children in a different order than Components.
+type orderedComponent interface {
+	drawnComponents() []Component
+}
+
+// clippingComponent is implemented by components that may hide their children outside an area.
+type clippingComponent interface {
+	clipArea() (image.Rectangle, bool)
+}
+
+func containsPoint(component Component, point image.Point) bool {
+	if tester, ok := component.(hitTester); ok {
+		return tester.containsPoint(point)
+	}
+	return point.In(component.Area())
+}
+
+// insideRoundedRect checks point against area with the corners rounded by radius.
+// radius: top_left top_right bottom_right bottom_left
+func insideRoundedRect(point image.Point, area image.Rectangle, radius [4]int) bool {
+	if !point.In(area) {
+		return false
+	}
+	var corners = [4]image.Point{
+		{X: area.Min.X + radius[0], Y: area.Min.Y + radius[0]},
+		{X: area.Max.X - radius[1], Y: area.Min.Y + radius[1]},
+		{X: area.Max.X - radius[2], Y: area.Max.Y - radius[2]},
+		{X: area.Min.X + radius[3], Y: area.Max.Y - radius[3]},
+	}
+	for i, corner := range corners {
+		var r = radius[i]
+		if r <= 0 {
+			continue
+		}
+		var dx, dy = point.X - corner.X, point.Y - corner.Y
+		var outside = (i == 0 && dx < 0 && dy < 0) ||
+			(i == 1 && dx > 0 && dy < 0) ||
+			(i == 2 && dx > 0 && dy > 0) ||
+			(i == 3 && dx < 0 && dy > 0)
+		if outside && dx*dx+dy*dy > r*r {
+			return false
+		}
+	}
+	return true
+}
+
+// hitTestPath returns the path from the root to the top-most component under point.
+func hitTestPath(components []Component, point image.Point) []Component {
+	for i := len(components) - 1; i >= 0; i-- {
+		if path := hitTestComponent(components[i], point); path != nil {
+			return path
+		}
+	}
+	return nil
+}
+
+func hitTestComponent(component Component, point image.Point) []Component {
+	if transformed, ok := component.(transformedComponent); ok {
+		point = transformed.untransformPoint(point)
+	}
+	var children = component.Components()
+	if ordered, ok := component.(orderedComponent); ok {
+		children = ordered.drawnComponents()
+	}
+	var visible = true
+	if clipping, ok := component.(clippingComponent); ok {
+		if area, clips := clipping.clipArea(); clips {
+			visible = point.In(area)
+		}
+	}
+	if !visible {
+		// floating children anchored to the screen are drawn outside the clip
+		var unclipped = []Component{}
+		for _, child := range children {
+			if _, anchor, _ := getPositionStyle(child); anchor == AnchorScreen && child.IsFloating() {
+				unclipped = append(unclipped, child)
+			}
+		}
+		children = unclipped
+	}
+	if path := hitTestPath(children, point); path != nil {
+		return append([]Component{component}, path...)
+	}
+	if _, isWindow := component.(Window); !isWindow && containsPoint(component, point) {
+		return []Component{component}
+	}
+	return nil
+}
+
+// pathViews returns the views in path that are not disabled.
+func pathViews(path []Component) []View {
+	var views = []View{}
+	for _, component := range path {
+		if view, ok := component.(View); ok && !view.disabled {
+			views = append(views, view)
+		}
+	}
+	return views
+}
+
+func containsView(views []View, view View) bool {
+	for _, v := range views {
+		if v == view {
+			return true
+		}
+	}
+	return false
+}
+
+// pointerDispatcher keeps the hovered and pressed views of a Window between updates.
+type pointerDispatcher struct {
+	hovered []View
+	pressed []View
+}
+
+func (d *pointerDispatcher) dispatch(path []Component, ctx *updateContext, dragged bool) {
+	var views = pathViews(path)
+	var event = PointerEvent{X: ctx.pointer.X, Y: ctx.pointer.Y}
+	if len(path) > 0 {
+		event.Target = path[len(path)-1]
+	}
+
+	// leave from the deepest view, enter from the outermost view
+	for i := len(d.hovered) - 1; i >= 0; i-- {
+		if !containsView(views, d.hovered[i]) {
+			d.hovered[i].hovered = false
+			d.hovered[i].handlers.leave.call(event)
+		}
+	}
+	for _, view := range views {
+		if !containsView(d.hovered, view) {
+			view.hovered = true
+			view.handlers.enter.call(event)
+		}
+	}
+	d.hovered = views
+
+	if ctx.justPressed {
+		d.pressed = views
+		for i := len(views) - 1; i >= 0; i-- {
+			views[i].pressed = true
+			views[i].handlers.down.call(event)
+		}
+	}
+	if ctx.justReleased {
+		for i := len(views) - 1; i >= 0; i-- {
+			views[i].handlers.up.call(event)
+		}
+		for i := len(views) - 1; i >= 0; i-- {
+			if !dragged && containsView(d.pressed, views[i]) {
+				views[i].handlers.click.call(event)
+			}
+		}
+		for _, view := range d.pressed {
+			view.pressed = false
+		}
+		d.pressed = nil
+	}
+}
+
+func (h PointerHandler) call(event PointerEvent) {
+	if h != nil {
+		h(event)
+	}
+}
+
+// HitTest returns the top-most component under the point, or nil.
+func (w Window) HitTest(x, y int) Component {
+	var path = hitTestPath(w.drawnComponents(), image.Point{X: x, Y: y})
+	if len(path) == 0 {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// Contains reports whether component is target or one of its ancestors.
+func Contains(component, target Component) bool {
+	if component == target {
+		return true
+	}
+	for _, child := range component.Components() {
+		if Contains(child, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v View) OnPointerEnter(handler PointerHandler) {
+	v.handlers.enter = handler
+}
+func (v View) OnPointerLeave(handler PointerHandler) {
+	v.handlers.leave = handler
+}
+func (v View) OnPointerDown(handler PointerHandler) {
+	v.handlers.down = handler
+}
+func (v View) OnPointerUp(handler PointerHandler) {
+	v.handlers.up = handler
+}
+func (v View) OnClick(handler PointerHandler) {
+	v.handlers.click = handler
+}
+
+func (v View) IsHovered() bool {
+	return v.hovered
+}
+func (v View) IsPressed() bool {
+	return v.pressed
+}
@@ -0,0 +1,89 @@
+package game_ui
+
+import (
+	"image"
+	"sort"
+)
+
+type AnchorType = string
+
+const (
+	AnchorParent AnchorType = "parent"
+	AnchorScreen AnchorType = "screen"
+)
+
+// positionedItem is implemented by floating components that can be placed by offsets.
+type positionedItem interface {
+	positionStyle() (offsets [4]*sizeSeg, anchor AnchorType, zIndex int)
+}
+
+func getPositionStyle(component Component) ([4]*sizeSeg, AnchorType, int) {
+	if item, ok := component.(positionedItem); ok {
+		return item.positionStyle()
+	}
+	return [4]*sizeSeg{}, AnchorParent, 0
+}
+
+// placeFloating returns the position of a floating component inside area.
+// Offsets that are not set keep the flow position.
+// offsets: top right bottom left
+func placeFloating(component Component, box layoutBox, area image.Rectangle, flow image.Point) image.Point {
+	var offsets, _, _ = getPositionStyle(component)
+	var top, right, bottom, left = offsets[0], offsets[1], offsets[2], offsets[3]
+
+	// both sides set stretches the component unless it has its own size
+	if item, ok := component.(flexItem); ok {
+		var fills, isFillItem = component.(fillItem)
+		var size = item.measure()
+		var stretched = false
+		if left != nil && right != nil && !(isFillItem && fills.fillsAxis(true)) {
+			size.X = max(size.X, area.Dx()-calcSize(box, *left)-calcSize(box, *right))
+			stretched = true
+		}
+		if top != nil && bottom != nil && !(isFillItem && fills.fillsAxis(false)) {
+			size.Y = max(size.Y, area.Dy()-calcSize(box, *top)-calcSize(box, *bottom))
+			stretched = true
+		}
+		if stretched {
+			item.setFixedSize(&size)
+		}
+	}
+
+	var size = component.GetSize()
+	var position = flow
+	if left != nil {
+		position.X = area.Min.X + calcSize(box, *left)
+	} else if right != nil {
+		position.X = area.Max.X - calcSize(box, *right) - size.X
+	}
+	if top != nil {
+		position.Y = area.Min.Y + calcSize(box, *top)
+	} else if bottom != nil {
+		position.Y = area.Max.Y - calcSize(box, *bottom) - size.Y
+	}
+	return position
+}
+
+// drawingOrder returns the indexes of the components in the order they are drawn:
+// floating components with a negative z-index, the flow, then the rest of the
+// floating components by z-index.
+func drawingOrder(components []Component) []int {
+	var order = make([]int, len(components))
+	for i := range order {
+		order[i] = i
+	}
+	var rank = func(i int) int {
+		if !components[i].IsFloating() {
+			return 0
+		}
+		var _, _, zIndex = getPositionStyle(components[i])
+		if zIndex < 0 {
+			return zIndex
+		}
+		return zIndex + 1
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return rank(order[a]) < rank(order[b])
+	})
+	return order
+}
@@ -0,0 +1,148 @@
+package game_ui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// viewRenderKey holds every resolved value that affects the pre-composited
+// border/background image of a View.
+type viewRenderKey struct {
+	width, height   int
+	border          [4]int
+	radius          [4]int
+	borderColor     [4]color.RGBA64
+	backgroundColor [4]color.RGBA64
+}
+
+type viewRenderCache struct {
+	key   viewRenderKey
+	image *ebiten.Image
+	valid bool
+}
+
+func colorKey(colors *[4]color.Color) [4]color.RGBA64 {
+	var key [4]color.RGBA64
+	if colors == nil {
+		return key
+	}
+	for i := range colors {
+		// a nil color is transparent, e.g. the rest of a single color
+		if colors[i] == nil {
+			continue
+		}
+		var r, g, b, a = colors[i].RGBA()
+		key[i] = color.RGBA64{R: uint16(r), G: uint16(g), B: uint16(b), A: uint16(a)}
+	}
+	return key
+}
+
+func hasAlpha(colors [4]color.RGBA64) bool {
+	return colors[0].A > 0 || colors[1].A > 0 || colors[2].A > 0 || colors[3].A > 0
+}
+
+// appendRoundedRect adds a closed rounded rectangle sub path.
+// radius: top_left top_right bottom_right bottom_left
+func appendRoundedRect(path *vector.Path, left, top, right, bottom float32, radius [4]int) {
+	path.MoveTo(left+float32(radius[0]), top)
+	path.LineTo(right-float32(radius[1]), top)
+	path.QuadTo(right, top, right, top+float32(radius[1]))
+	path.LineTo(right, bottom-float32(radius[2]))
+	path.QuadTo(right, bottom, right-float32(radius[2]), bottom)
+	path.LineTo(left+float32(radius[3]), bottom)
+	path.QuadTo(left, bottom, left, bottom-float32(radius[3]))
+	path.LineTo(left, top+float32(radius[0]))
+	path.QuadTo(left, top, left+float32(radius[0]), top)
+	path.Close()
+}
+
+func gradientVertices(colors [4]color.RGBA64, w, h float32) []ebiten.Vertex {
+	var vertex = func(x, y float32, c color.RGBA64) ebiten.Vertex {
+		return ebiten.Vertex{
+			DstX: x, DstY: y, SrcX: 1, SrcY: 1,
+			ColorR: float32(c.R) / 0xffff,
+			ColorG: float32(c.G) / 0xffff,
+			ColorB: float32(c.B) / 0xffff,
+			ColorA: float32(c.A) / 0xffff,
+		}
+	}
+	return []ebiten.Vertex{
+		vertex(0, 0, colors[0]),
+		vertex(w, 0, colors[1]),
+		vertex(0, h, colors[3]),
+		vertex(w, h, colors[2]),
+	}
+}
+
+var gradientIndices = []uint16{0, 1, 2, 1, 3, 2}
+
+// fillGradientPath draws path onto dst filled with a four corner gradient.
+func fillGradientPath(dst *ebiten.Image, path *vector.Path, fillRule vector.FillRule, colors [4]color.RGBA64) {
+	var size = dst.Bounds().Size()
+	var w, h = float32(size.X), float32(size.Y)
+
+	// Create off-screen buffer
+	var maskBuffer = ebiten.NewImage(size.X, size.Y)
+	defer maskBuffer.Deallocate()
+
+	// Draw mask (path shape)
+	drawOp := &vector.DrawPathOptions{}
+	drawOp.ColorScale.ScaleWithColor(color.White)
+	vector.FillPath(maskBuffer, path, &vector.FillOptions{FillRule: fillRule}, drawOp)
+
+	// Composite gradient with BlendSourceIn (clip by mask alpha)
+	op := &ebiten.DrawTrianglesOptions{}
+	op.Blend = ebiten.BlendSourceIn
+	maskBuffer.DrawTriangles(gradientVertices(colors, w, h), gradientIndices, emptySubImage, op)
+
+	dst.DrawImage(maskBuffer, nil)
+}
+
+func (c *viewRenderCache) render(key viewRenderKey) *ebiten.Image {
+	if c.valid && c.key == key {
+		return c.image
+	}
+	c.dispose()
+	c.key = key
+	c.valid = true
+	if key.width <= 0 || key.height <= 0 {
+		return nil
+	}
+
+	var w, h = float32(key.width), float32(key.height)
+	var top, right, bottom, left = float32(key.border[0]), float32(key.border[1]), float32(key.border[2]), float32(key.border[3])
+	var drawBorder = (top+bottom > 0 || left+right > 0) && hasAlpha(key.borderColor)
+	var drawBackground = hasAlpha(key.backgroundColor)
+	if !drawBorder && !drawBackground {
+		return nil
+	}
+
+	c.image = ebiten.NewImage(key.width, key.height)
+
+	// draw border
+	if drawBorder {
+		var path = vector.Path{}
+		appendRoundedRect(&path, 0, 0, w, h, key.radius)
+		appendRoundedRect(&path, left, top, w-right, h-bottom, key.radius)
+		fillGradientPath(c.image, &path, vector.FillRuleEvenOdd, key.borderColor)
+	}
+
+	// draw base
+	if drawBackground {
+		var path = vector.Path{}
+		appendRoundedRect(&path, left, top, w-right, h-bottom, key.radius)
+		fillGradientPath(c.image, &path, vector.FillRuleNonZero, key.backgroundColor)
+	}
+
+	return c.image
+}
+
+func (c *viewRenderCache) dispose() {
+	if c.image != nil {
+		c.image.Deallocate()
+		c.image = nil
+	}
+	c.valid = false
+}
@@ -0,0 +1,409 @@
+package game_ui
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// TextSpan is a part of a Text drawn with its own style.
+// A span with an Image or an Icon is drawn as the image sitting on the baseline instead of its Text.
+type TextSpan struct {
+	Text  string
+	Image *ebiten.Image
+	// name given to RegisterIcon, the image follows the active input device
+	Icon  string
+	Style SpanStyle
+	// msec the reveal of the Text waits before the span, see TextReveal
+	Pause int64
+	// reported by the reveal of the Text when it reaches the span
+	Marker string
+}
+
+// SpanStyle overrides the style of the Text for a span, values that are not set follow the Text.
+type SpanStyle struct {
+	Color  *color.Color
+	Font   *TextFont
+	Size   *float64 // px
+	Weight *FontWeight
+	// multiplies the speed of the reveal of the Text, 2 shows the characters twice as fast
+	Speed  *float64
+	Effect *GlyphEffect
+}
+
+func mergeSpanStyle(target SpanStyle, styles []SpanStyle) SpanStyle {
+	for i := range styles {
+		if styles[i].Color != nil {
+			target.Color = styles[i].Color
+		}
+		if styles[i].Font != nil {
+			target.Font = styles[i].Font
+		}
+		if styles[i].Size != nil {
+			target.Size = styles[i].Size
+		}
+		if styles[i].Weight != nil {
+			target.Weight = styles[i].Weight
+		}
+		if styles[i].Speed != nil {
+			target.Speed = styles[i].Speed
+		}
+		if styles[i].Effect != nil {
+			target.Effect = styles[i].Effect
+		}
+	}
+	return target
+}
+
+// Span returns a span of str, for NewRichText.
+func Span(str string, styles ...SpanStyle) TextSpan {
+	return TextSpan{Text: str, Style: mergeSpanStyle(SpanStyle{}, styles)}
+}
+
+// IconSpan returns a span of the icon named name, as tall as the size of the font.
+func IconSpan(name string, styles ...SpanStyle) TextSpan {
+	return TextSpan{Icon: name, Style: mergeSpanStyle(SpanStyle{}, styles)}
+}
+
+// textRun is the resolved style of a span.
+type textRun struct {
+	face  text.Face
+	color color.Color
+	// px, the size of the font
+	size float64
+	// scale of a face that cannot be resized
+	scale float64
+	// px the glyphs are moved by
+	dx, dy float64
+	// px from the top of the line to the baseline and from the baseline to the bottom
+	ascent, descent int
+	// of the reveal
+	speed  float64
+	effect GlyphEffect
+}
+
+type textGlyph struct {
+	text  string
+	image *ebiten.Image
+	run   int
+	// the character the glyph is revealed with
+	index int
+	// bytes from the start of the text of the spans, an image is one "\uFFFC"
+	offset  int
+	x       float64 // px from the start of the line
+	advance float64
+	// px above the baseline
+	height float64
+	// width of the hyphen a soft hyphen is drawn as when the line breaks after it
+	hyphen float64
+}
+
+type textLine struct {
+	glyphs []textGlyph
+	// px from the left of the text, by the alignment
+	x     float64
+	width float64
+	// whether the text goes on in the next line without a newline
+	wrapped bool
+	// px after the last glyph and after the last glyph that is not a space
+	end, content    float64
+	ascent, descent int
+	// offset of the text the line starts at
+	start int
+}
+
+// textChar is a character of the text in the order it is revealed, an image is "\uFFFC".
+type textChar struct {
+	text string
+	run  int
+}
+
+type textLayout struct {
+	runs  []textRun
+	lines []textLine
+	// px the lines are aligned in, 0 for the widest line
+	width float64
+	chars []textChar
+	// whether a run has a glyph effect
+	animated bool
+}
+
+func newTextRun(style TextStyle, span SpanStyle, lineHeight int) textRun {
+	var font = style.Font
+	if span.Font != nil {
+		font = span.Font
+	}
+	var run = textRun{color: *style.Color, size: font.size, speed: 1}
+	if span.Color != nil {
+		run.color = *span.Color
+	}
+	if span.Size != nil {
+		run.size = *span.Size
+	} else if style.Size != nil {
+		run.size = *style.Size
+	}
+	if span.Speed != nil && *span.Speed > 0 {
+		run.speed = *span.Speed
+	}
+	if span.Effect != nil {
+		run.effect = *span.Effect
+	}
+	var weight = FontWeightNormal
+	if span.Weight != nil {
+		weight = *span.Weight
+	} else if style.Weight != nil {
+		weight = *style.Weight
+	}
+	var face = font.resolve(run.size, weight)
+	run.face, run.scale, run.dx, run.dy = face.face, face.scale, face.dx, face.dy
+	var height = float64(lineHeight)
+	if font.size > 0 {
+		height *= run.size / font.size
+	}
+	// the glyphs are centered in the line like the half-leading of CSS
+	var metrics = run.face.Metrics()
+	var ascent, descent = metrics.HAscent * run.scale, metrics.HDescent * run.scale
+	run.ascent = int(math.Round((height-ascent-descent)/2 + ascent))
+	run.descent = int(math.Round(height)) - run.ascent
+	return run
+}
+
+func (l *textLine) add(glyph textGlyph, run textRun, space bool) {
+	glyph.x = l.end
+	l.glyphs = append(l.glyphs, glyph)
+	l.end += glyph.advance
+	if !space {
+		l.content = l.end
+	}
+	l.width = l.end
+	l.ascent = max(l.ascent, run.ascent, int(math.Ceil(glyph.height)))
+	l.descent = max(l.descent, run.descent)
+}
+
+func (l textLine) height() int {
+	return l.ascent + l.descent
+}
+
+// layoutText places the glyphs of spans in lines no wider than maxWidth px, see lineBreaker.
+func layoutText(spans []TextSpan, style TextStyle, lineHeight int, maxWidth float64) textLayout {
+	var layout = textLayout{}
+	var base = newTextRun(style, SpanStyle{}, lineHeight)
+	for _, span := range spans {
+		var run = newTextRun(style, span.Style, lineHeight)
+		layout.runs = append(layout.runs, run)
+		layout.animated = layout.animated || run.effect != ""
+	}
+	var items = collectTextItems(spans, layout.runs)
+	for i := range items {
+		var glyph = &items[i].glyph
+		if items[i].newline || (glyph.text == "" && glyph.image == nil) {
+			// a newline or a soft hyphen goes with the character before it
+			glyph.index = max(len(layout.chars)-1, 0)
+			continue
+		}
+		var char = textChar{text: glyph.text, run: glyph.run}
+		if glyph.image != nil {
+			char.text = "\uFFFC"
+		}
+		glyph.index = len(layout.chars)
+		layout.chars = append(layout.chars, char)
+	}
+	var breaker = lineBreaker{layout: &layout, maxWidth: maxWidth, hyphenate: style.Hyphenate}
+	// an empty line takes the height of the span it starts with
+	if len(items) > 0 {
+		base = layout.runs[items[0].glyph.run]
+	}
+	breaker.line = textLine{ascent: base.ascent, descent: base.descent}
+	breaker.breakLines(items)
+	if style.MaxLines != nil && len(layout.lines) > max(*style.MaxLines, 1) {
+		var maxLines = max(*style.MaxLines, 1)
+		if style.Overflow != nil && *style.Overflow == Ellipsis {
+			layout.ellipsize(maxLines-1, maxWidth)
+		} else {
+			layout.lines = layout.lines[:maxLines]
+		}
+	}
+	if style.Align != nil && *style.Align != AlignLeft {
+		layout.align(*style.Align, maxWidth)
+	}
+	return layout
+}
+
+// ellipsize ends the line at index with an ellipsis and drops the lines after it.
+// A wrapped line is filled with the glyphs of the next line as far as they fit with the ellipsis.
+func (l *textLayout) ellipsize(index int, maxWidth float64) {
+	var line = &l.lines[index]
+	var glyphs = line.glyphs
+	if line.wrapped && index+1 < len(l.lines) {
+		var end = 0.0
+		glyphs = []textGlyph{}
+		for _, glyph := range append(append([]textGlyph{}, line.glyphs...), l.lines[index+1].glyphs...) {
+			// the hyphen of a broken word is not needed when the word goes on
+			if glyph.hyphen > 0 {
+				continue
+			}
+			glyph.x = end
+			end += glyph.advance
+			glyphs = append(glyphs, glyph)
+		}
+	}
+	l.lines = l.lines[:index+1]
+	var run = 0
+	if len(glyphs) > 0 {
+		run = glyphs[len(glyphs)-1].run
+	}
+	if run >= len(l.runs) {
+		return
+	}
+	var ellipsis = textGlyph{text: "…", run: run, advance: l.runs[run].measure("…", "")}
+	for len(glyphs) > 0 {
+		var last = glyphs[len(glyphs)-1]
+		// spaces do not stay before the ellipsis
+		if last.image != nil || strings.TrimSpace(last.text) != "" {
+			if last.x+last.advance+ellipsis.advance <= maxWidth {
+				break
+			}
+		}
+		glyphs = glyphs[:len(glyphs)-1]
+	}
+	if len(glyphs) > 0 {
+		var last = glyphs[len(glyphs)-1]
+		ellipsis.x = last.x + last.advance
+		ellipsis.index = last.index
+	}
+	line.glyphs = append(glyphs, ellipsis)
+	line.width = ellipsis.x + ellipsis.advance
+	line.wrapped = false
+}
+
+// align moves the lines in width px, or in the widest line when width is not finite.
+func (l *textLayout) align(align TextAlign, width float64) {
+	if math.IsInf(width, 1) {
+		width = 0
+		for _, line := range l.lines {
+			width = max(width, line.width)
+		}
+	}
+	l.width = width
+	for i := range l.lines {
+		var line = &l.lines[i]
+		var space = width - line.width
+		switch align {
+		case AlignCenter:
+			line.x = math.Floor(space / 2)
+		case AlignRight:
+			line.x = space
+		case AlignJustify:
+			// the last line of a paragraph stays at the left
+			if line.wrapped {
+				line.justify(width)
+			}
+		}
+	}
+}
+
+// justify spreads the glyphs to width px over the spaces between words, or between every glyph when there are none.
+func (l *textLine) justify(width float64) {
+	var end = len(l.glyphs)
+	for end > 0 && strings.TrimSpace(l.glyphs[end-1].text) == "" && l.glyphs[end-1].image == nil {
+		end--
+	}
+	if end < 2 {
+		return
+	}
+	var isGap = func(glyph textGlyph) bool {
+		return glyph.image == nil && glyph.text != "" && strings.TrimSpace(glyph.text) == ""
+	}
+	var gaps = 0
+	for _, glyph := range l.glyphs[:end] {
+		if isGap(glyph) {
+			gaps++
+		}
+	}
+	var everyGlyph = gaps == 0
+	if everyGlyph {
+		gaps = end - 1
+	}
+	var extra = (width - l.width) / float64(gaps)
+	var shift = 0.0
+	for i := range l.glyphs {
+		l.glyphs[i].x += shift
+		if (everyGlyph && i < end-1) || (!everyGlyph && isGap(l.glyphs[i])) {
+			shift += extra
+		}
+	}
+	l.width = width
+}
+
+func (l textLayout) size() image.Point {
+	var size = image.Point{}
+	for _, line := range l.lines {
+		size.X = max(size.X, int(math.Round(line.width)))
+		size.Y += line.height()
+	}
+	if size.X > 0 {
+		size.X -= 1 // 既存挙動に合わせる
+	}
+	if l.width > 0 {
+		size.X = int(math.Round(l.width))
+	}
+	return size
+}
+
+// draw draws the glyphs with the top left of the first line at x, y, a run of glyphs at a time,
+// or every glyph on its own in its style with a styler.
+func (l textLayout) draw(screen *ebiten.Image, x, y int, styler glyphStyler) {
+	for _, line := range l.lines {
+		var baseline = y + line.ascent
+		for start := 0; start < len(line.glyphs); {
+			var glyph = line.glyphs[start]
+			var run = l.runs[glyph.run]
+			var style = glyphStyle{}
+			if styler != nil {
+				style = styler(glyph, run)
+			}
+			if style.hidden {
+				start++
+				continue
+			}
+			if glyph.image != nil {
+				drawInlineImage(screen, glyph, float64(x)+line.x+style.dx, float64(baseline)+style.dy)
+				start++
+				continue
+			}
+			var str = glyph.text
+			var end = start + 1
+			for styler == nil && end < len(line.glyphs) && line.glyphs[end].run == glyph.run && line.glyphs[end].image == nil {
+				str += line.glyphs[end].text
+				end++
+			}
+			var c = run.color
+			if style.color != nil {
+				c = style.color
+			}
+			op := &text.DrawOptions{}
+			op.GeoM.Scale(run.scale, run.scale)
+			// the origin of the text is the top of the ascent
+			var top = float64(baseline) - run.face.Metrics().HAscent*run.scale
+			op.GeoM.Translate(math.Round(float64(x)+line.x+glyph.x+run.dx+style.dx), math.Round(top+run.dy+style.dy))
+			op.ColorScale.ScaleWithColor(c)
+			text.Draw(screen, str, run.face, op)
+			start = end
+		}
+		y += line.height()
+	}
+}
+
+// drawInlineImage draws the image of glyph with its bottom on the baseline.
+func drawInlineImage(screen *ebiten.Image, glyph textGlyph, x, baseline float64) {
+	var size = glyph.image.Bounds().Size()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(glyph.advance/float64(size.X), glyph.height/float64(size.Y))
+	op.GeoM.Translate(math.Round(x+glyph.x), baseline-glyph.height)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(glyph.image, op)
+}
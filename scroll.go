@@ -0,0 +1,261 @@
+package game_ui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+type OverflowType = string
+
+const (
+	Visible OverflowType = "visible"
+	Hidden  OverflowType = "hidden"
+	Scroll  OverflowType = "scroll"
+)
+
+type ScrollbarStyle struct {
+	Width *sizeSeg
+	/* top_left top_right bottom_right bottom_left */
+	Color, TrackColor *[4]color.Color
+	Radius            *[4]int
+}
+
+const (
+	wheelScrollSpeed = 20  // px per wheel step
+	stickScrollSpeed = 0.6 // px per msec at full tilt
+	dragThreshold    = 4   // px before a press turns into a drag
+	minThumbLength   = 16  // px
+)
+
+var defaultScrollbarStyle = ScrollbarStyle{
+	Width:      Px(4),
+	Color:      ColorCode1(0xffffff88),
+	TrackColor: ColorCode1(0xffffff22),
+	Radius:     Radius1(2),
+}
+
+func mergeScrollbarStyle(target ScrollbarStyle, style *ScrollbarStyle) ScrollbarStyle {
+	if style == nil {
+		return target
+	}
+	if style.Width != nil {
+		target.Width = style.Width
+	}
+	if style.Color != nil {
+		target.Color = style.Color
+	}
+	if style.TrackColor != nil {
+		target.TrackColor = style.TrackColor
+	}
+	if style.Radius != nil {
+		target.Radius = style.Radius
+	}
+	return target
+}
+
+type scrollState struct {
+	offset image.Point
+	max    image.Point
+	// padding box of the last draw, where the children are clipped
+	clipArea  image.Rectangle
+	layer     *ebiten.Image
+	mask      *ebiten.Image
+	maskKey   viewRenderKey
+	scrollbar [4]viewRenderCache
+}
+
+// scrollDrag is a press on a scroll view that may turn into a drag.
+type scrollDrag struct {
+	view     View
+	from     image.Point
+	offset   image.Point
+	dragging bool
+}
+
+func isClipping(style ViewStyle) bool {
+	return style.Overflow != nil && *style.Overflow != Visible
+}
+
+func isScrolling(style ViewStyle) bool {
+	return style.Overflow != nil && *style.Overflow == Scroll
+}
+
+func (v View) ScrollOffset() image.Point {
+	return v.scroll.offset
+}
+
+func (v View) ScrollTo(x, y int) {
+	v.scroll.offset = image.Point{
+		X: max(min(x, v.scroll.max.X), 0),
+		Y: max(min(y, v.scroll.max.Y), 0),
+	}
+}
+
+func (v View) ScrollBy(dx, dy int) {
+	v.ScrollTo(v.scroll.offset.X+dx, v.scroll.offset.Y+dy)
+}
+
+func (v View) canScroll() bool {
+	var style = v.getStyle()
+	return isScrolling(style) && (v.scroll.max.X > 0 || v.scroll.max.Y > 0)
+}
+
+// clipLayer returns a cleared offscreen image covering the clip area for the clipped children,
+// they are drawn on it in the coordinates of screen.
+func (s *scrollState) clipLayer(screen *ebiten.Image) *ebiten.Image {
+	var bounds = s.clipArea.Intersect(screen.Bounds())
+	if bounds.Empty() {
+		bounds = image.Rectangle{Min: s.clipArea.Min, Max: s.clipArea.Min.Add(image.Point{X: 1, Y: 1})}
+	}
+	if s.layer != nil && s.layer.Bounds() != bounds {
+		s.layer.Deallocate()
+		s.layer = nil
+	}
+	if s.layer == nil {
+		s.layer = ebiten.NewImageWithOptions(bounds, nil)
+	}
+	s.layer.Clear()
+	return s.layer
+}
+
+// drawClipped draws the layer to screen inside the clip area rounded by radius.
+func (s *scrollState) drawClipped(screen *ebiten.Image, radius [4]int) {
+	var area = s.clipArea
+	if area.Empty() || s.layer.Bounds().Intersect(area).Empty() {
+		return
+	}
+	if radius != [4]int{} {
+		var key = viewRenderKey{width: area.Dx(), height: area.Dy(), radius: radius}
+		if s.mask == nil || s.maskKey != key {
+			if s.mask != nil {
+				s.mask.Deallocate()
+			}
+			s.maskKey = key
+			s.mask = ebiten.NewImage(area.Dx(), area.Dy())
+			var path = vector.Path{}
+			appendRoundedRect(&path, 0, 0, float32(area.Dx()), float32(area.Dy()), radius)
+			drawOp := &vector.DrawPathOptions{}
+			drawOp.ColorScale.ScaleWithColor(color.White)
+			vector.FillPath(s.mask, &path, nil, drawOp)
+		}
+		maskOp := &ebiten.DrawImageOptions{}
+		maskOp.GeoM.Translate(float64(area.Min.X), float64(area.Min.Y))
+		maskOp.Blend = ebiten.BlendDestinationIn
+		s.layer.DrawImage(s.mask, maskOp)
+	}
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(s.layer.Bounds().Min.X), float64(s.layer.Bounds().Min.Y))
+	screen.DrawImage(s.layer, op)
+}
+
+func (s *scrollState) drawScrollbars(screen *ebiten.Image, box layoutBox, style *ScrollbarStyle) {
+	var merged = mergeScrollbarStyle(defaultScrollbarStyle, style)
+	var width = calcSize(box, *merged.Width)
+	var area = s.clipArea
+	if width <= 0 || area.Empty() {
+		return
+	}
+	var draw = func(cache *viewRenderCache, rect image.Rectangle, colors *[4]color.Color) {
+		var radius = *merged.Radius
+		for i := range radius {
+			radius[i] = min(radius[i], rect.Dx()/2, rect.Dy()/2)
+		}
+		var img = cache.render(viewRenderKey{width: rect.Dx(), height: rect.Dy(), radius: radius, backgroundColor: colorKey(colors)})
+		if img != nil {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(rect.Min.X), float64(rect.Min.Y))
+			screen.DrawImage(img, op)
+		}
+	}
+	var thumb = func(track, viewport, offset, maxOffset int) (int, int) {
+		var length = max(track*viewport/(viewport+maxOffset), min(minThumbLength, track))
+		return (track - length) * offset / maxOffset, length
+	}
+	if s.max.Y > 0 {
+		var track = image.Rect(area.Max.X-width, area.Min.Y, area.Max.X, area.Max.Y)
+		var position, length = thumb(track.Dy(), area.Dy(), s.offset.Y, s.max.Y)
+		draw(&s.scrollbar[0], track, merged.TrackColor)
+		draw(&s.scrollbar[1], image.Rect(track.Min.X, track.Min.Y+position, track.Max.X, track.Min.Y+position+length), merged.Color)
+	}
+	if s.max.X > 0 {
+		var track = image.Rect(area.Min.X, area.Max.Y-width, area.Max.X, area.Max.Y)
+		if s.max.Y > 0 {
+			track.Max.X -= width
+		}
+		var position, length = thumb(track.Dx(), area.Dx(), s.offset.X, s.max.X)
+		draw(&s.scrollbar[2], track, merged.TrackColor)
+		draw(&s.scrollbar[3], image.Rect(track.Min.X+position, track.Min.Y, track.Min.X+position+length, track.Max.Y), merged.Color)
+	}
+}
+
+func (s *scrollState) dispose() {
+	if s.layer != nil {
+		s.layer.Deallocate()
+		s.layer = nil
+	}
+	if s.mask != nil {
+		s.mask.Deallocate()
+		s.mask = nil
+	}
+	for i := range s.scrollbar {
+		s.scrollbar[i].dispose()
+	}
+}
+
+// findScrollView returns the deepest scrollable view in the hit path, or nil.
+func findScrollView(path []Component) View {
+	for i := len(path) - 1; i >= 0; i-- {
+		if view, ok := path[i].(View); ok && view.canScroll() {
+			return view
+		}
+	}
+	return nil
+}
+
+func findFirstScrollView(components []Component) View {
+	var found View
+	walkComponents(components, 0, func(component Component, depth int) {
+		if view, ok := component.(View); ok && found == nil && view.canScroll() {
+			found = view
+		}
+	})
+	return found
+}
+
+// updateScroll scrolls the views with the mouse wheel, pointer drags and the right stick.
+func updateScroll(components []Component, path []Component, ctx *updateContext, drag *scrollDrag) {
+	var hovered = findScrollView(path)
+	if hovered != nil && (ctx.wheel[0] != 0 || ctx.wheel[1] != 0) {
+		hovered.ScrollBy(int(-ctx.wheel[0]*wheelScrollSpeed), int(-ctx.wheel[1]*wheelScrollSpeed))
+	}
+
+	if ctx.stick[0] != 0 || ctx.stick[1] != 0 {
+		var target = hovered
+		if target == nil {
+			target = findFirstScrollView(components)
+		}
+		if target != nil {
+			var speed = stickScrollSpeed * float64(ctx.delta)
+			target.ScrollBy(int(ctx.stick[0]*speed), int(ctx.stick[1]*speed))
+		}
+	}
+
+	if ctx.justPressed && hovered != nil {
+		*drag = scrollDrag{view: hovered, from: ctx.pointer, offset: hovered.scroll.offset}
+	}
+	if drag.view != nil {
+		var moved = ctx.pointer.Sub(drag.from)
+		if !drag.dragging && (moved.X*moved.X+moved.Y*moved.Y) > dragThreshold*dragThreshold {
+			drag.dragging = true
+		}
+		if drag.dragging {
+			drag.view.ScrollTo(drag.offset.X-moved.X, drag.offset.Y-moved.Y)
+		}
+		if !ctx.pressed {
+			*drag = scrollDrag{}
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package game_ui
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// BoxShadow follows the rounded border box of a View.
+// An inset shadow is drawn inside the padding box, above the background and below the children.
+type BoxShadow struct {
+	X, Y int // px
+	// px, up to maxShadowBlur
+	Blur   int
+	Spread int
+	Color  *color.Color
+	Inset  bool
+}
+
+const maxShadowBlur = 32
+
+var defaultShadowColor color.Color = color.RGBA{A: 0x80}
+
+// Shadows stacks the shadows, the first one is drawn on top.
+func Shadows(shadows ...BoxShadow) *[]BoxShadow {
+	return &shadows
+}
+
+var blurShaderSource = []byte(`//kage:unit pixels
+
+package main
+
+var Direction vec2
+var Radius float
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	if Radius <= 0 {
+		return imageSrc0At(srcPos)
+	}
+	sigma := Radius / 2
+	sum := vec4(0)
+	weight := 0.0
+	for i := 0; i < 65; i++ {
+		d := float(i) - 32
+		if abs(d) <= Radius {
+			w := exp(-(d * d) / (2 * sigma * sigma))
+			sum += imageSrc0At(srcPos+Direction*d) * w
+			weight += w
+		}
+	}
+	return sum / weight
+}
+`)
+
+var blurShader *ebiten.Shader
+
+func getBlurShader() *ebiten.Shader {
+	if blurShader == nil {
+		var shader, err = ebiten.NewShader(blurShaderSource)
+		if err != nil {
+			panic(err)
+		}
+		blurShader = shader
+	}
+	return blurShader
+}
+
+// blurImage returns a new image of src blurred by a gaussian blur of radius px.
+func blurImage(src *ebiten.Image, radius int) *ebiten.Image {
+	var size = src.Bounds().Size()
+	var dst = ebiten.NewImage(size.X, size.Y)
+	var horizontal = ebiten.NewImage(size.X, size.Y)
+	defer horizontal.Deallocate()
+	blurImageTo(dst, horizontal, src, radius)
+	return dst
+}
+
+// blurImageTo draws src blurred by radius px on dst, through tmp. They are of the same size.
+func blurImageTo(dst, tmp, src *ebiten.Image, radius int) {
+	var size = src.Bounds().Size()
+	var pass = func(from, to *ebiten.Image, direction []float32) {
+		op := &ebiten.DrawRectShaderOptions{}
+		op.Images[0] = from
+		op.Uniforms = map[string]any{
+			"Direction": direction,
+			"Radius":    float32(radius),
+		}
+		to.DrawRectShader(size.X, size.Y, getBlurShader(), op)
+	}
+	pass(src, tmp, []float32{1, 0})
+	pass(tmp, dst, []float32{0, 1})
+}
+
+// shadowRenderKey holds every resolved value that affects the image of a BoxShadow.
+type shadowRenderKey struct {
+	width, height int
+	border        [4]int
+	radius        [4]int
+	x, y          int
+	blur, spread  int
+	color         color.RGBA64
+	inset         bool
+}
+
+type shadowRenderCache struct {
+	key   shadowRenderKey
+	image *ebiten.Image
+	// position of the image relative to the border box
+	offset image.Point
+	valid  bool
+}
+
+func newShadowRenderKey(shadow BoxShadow, width, height int, border, radius [4]int) shadowRenderKey {
+	var c = defaultShadowColor
+	if shadow.Color != nil {
+		c = *shadow.Color
+	}
+	return shadowRenderKey{
+		width:  width,
+		height: height,
+		border: border,
+		radius: radius,
+		x:      shadow.X,
+		y:      shadow.Y,
+		blur:   min(max(shadow.Blur, 0), maxShadowBlur),
+		spread: shadow.Spread,
+		color:  colorKey(&[4]color.Color{c})[0],
+		inset:  shadow.Inset,
+	}
+}
+
+// spreadRadius grows or shrinks the rounded corners with the shape like CSS does.
+func spreadRadius(radius [4]int, spread int) [4]int {
+	for i := range radius {
+		if radius[i] > 0 {
+			radius[i] = max(radius[i]+spread, 0)
+		}
+	}
+	return radius
+}
+
+func fillRoundedRect(dst *ebiten.Image, left, top, right, bottom float32, radius [4]int, c color.Color, blend ebiten.Blend) {
+	var path = vector.Path{}
+	appendRoundedRect(&path, left, top, right, bottom, radius)
+	drawOp := &vector.DrawPathOptions{}
+	drawOp.ColorScale.ScaleWithColor(c)
+	drawOp.Blend = blend
+	vector.FillPath(dst, &path, nil, drawOp)
+}
+
+func (c *shadowRenderCache) render(key shadowRenderKey) (*ebiten.Image, image.Point) {
+	if c.valid && c.key == key {
+		return c.image, c.offset
+	}
+	c.dispose()
+	c.key = key
+	c.valid = true
+	if key.width <= 0 || key.height <= 0 || key.color.A == 0 {
+		return nil, image.Point{}
+	}
+	if key.inset {
+		c.image, c.offset = renderInsetShadow(key)
+	} else {
+		c.image, c.offset = renderOuterShadow(key)
+	}
+	return c.image, c.offset
+}
+
+func renderOuterShadow(key shadowRenderKey) (*ebiten.Image, image.Point) {
+	var shape = image.Rect(key.x-key.spread, key.y-key.spread, key.width+key.x+key.spread, key.height+key.y+key.spread)
+	if shape.Empty() {
+		return nil, image.Point{}
+	}
+	var bounds = shape.Inset(-key.blur)
+	var shapeImage = ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	defer shapeImage.Deallocate()
+	var local = shape.Sub(bounds.Min)
+	fillRoundedRect(shapeImage, float32(local.Min.X), float32(local.Min.Y), float32(local.Max.X), float32(local.Max.Y), spreadRadius(key.radius, key.spread), key.color, ebiten.BlendSourceOver)
+
+	var img = blurImage(shapeImage, key.blur)
+	// the shadow is not seen through the box
+	var box = image.Rect(0, 0, key.width, key.height).Sub(bounds.Min)
+	fillRoundedRect(img, float32(box.Min.X), float32(box.Min.Y), float32(box.Max.X), float32(box.Max.Y), key.radius, color.White, ebiten.BlendDestinationOut)
+	return img, bounds.Min
+}
+
+func renderInsetShadow(key shadowRenderKey) (*ebiten.Image, image.Point) {
+	var top, right, bottom, left = key.border[0], key.border[1], key.border[2], key.border[3]
+	var paddingBox = image.Rect(left, top, key.width-right, key.height-bottom)
+	if paddingBox.Empty() {
+		return nil, image.Point{}
+	}
+	// filled beyond the padding box so that the blur does not fade at the edges
+	var bounds = paddingBox.Inset(-key.blur - max(key.x, -key.x) - max(key.y, -key.y))
+	var shapeImage = ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	defer shapeImage.Deallocate()
+	shapeImage.Fill(key.color)
+	var hole = paddingBox.Inset(key.spread).Add(image.Point{X: key.x, Y: key.y}).Sub(bounds.Min)
+	if !hole.Empty() {
+		fillRoundedRect(shapeImage, float32(hole.Min.X), float32(hole.Min.Y), float32(hole.Max.X), float32(hole.Max.Y), spreadRadius(key.radius, -key.spread), color.White, ebiten.BlendDestinationOut)
+	}
+	var blurred = blurImage(shapeImage, key.blur)
+	defer blurred.Deallocate()
+
+	var img = ebiten.NewImage(paddingBox.Dx(), paddingBox.Dy())
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(bounds.Min.X-paddingBox.Min.X), float64(bounds.Min.Y-paddingBox.Min.Y))
+	img.DrawImage(blurred, op)
+	fillRoundedRect(img, 0, 0, float32(paddingBox.Dx()), float32(paddingBox.Dy()), key.radius, color.White, ebiten.BlendDestinationIn)
+	return img, paddingBox.Min
+}
+
+func (c *shadowRenderCache) dispose() {
+	if c.image != nil {
+		c.image.Deallocate()
+		c.image = nil
+	}
+	c.valid = false
+}
+
+// drawShadows draws the inset or the outer shadows of a View whose border box is at x, y.
+func drawShadows(screen *ebiten.Image, caches []shadowRenderCache, shadows []BoxShadow, inset bool, x, y, width, height int, border, radius [4]int) {
+	// the first shadow is on top
+	for i := len(shadows) - 1; i >= 0; i-- {
+		if shadows[i].Inset != inset {
+			continue
+		}
+		var img, offset = caches[i].render(newShadowRenderKey(shadows[i], width, height, border, radius))
+		if img == nil {
+			continue
+		}
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x+offset.X), float64(y+offset.Y))
+		screen.DrawImage(img, op)
+	}
+}
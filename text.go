@@ -3,47 +3,68 @@ package game_ui
 import (
 	"image"
 	"image/color"
-	"strings"
+	"math"
 
-	"github.com/hajimehoshi/bitmapfont/v4"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/text"
-	"golang.org/x/image/font"
-	"golang.org/x/image/math/fixed"
 )
 
+type TextAlign = string
+
+const (
+	AlignLeft   TextAlign = "left"
+	AlignCenter TextAlign = "center"
+	AlignRight  TextAlign = "right"
+	// spreads the wrapped lines to the width, the last line of a paragraph stays at the left
+	AlignJustify TextAlign = "justify"
+)
+
+// Ellipsis is the Overflow of a Text that ends with "…" where it is cut.
+const Ellipsis OverflowType = "ellipsis"
+
 type textComponent struct {
-	str        string
-	size       *image.Point
-	style      TextStyle
-	screenSize image.Point
-	drawnArea  image.Rectangle
+	spans       []TextSpan
+	layout      textLayout
+	size        *image.Point
+	style       TextStyle
+	box         layoutBox
+	boxAssigned bool
+	drawnArea   image.Rectangle
+	transform   transformState
+	effects     textEffectCache
+	// iconGeneration the layout was made with
+	icons  int
+	reveal textReveal
+	// time of the last update, for the glyph effects
+	now int64
 }
 type Text = *textComponent
 type TextStyle struct {
 	Color      *color.Color
 	LineHeight *sizeSeg
 	Font       *TextFont
-	Width      *sizeSeg
-}
-type TextFont struct {
-	face        font.Face
-	xAdjustment int
-	yAdjustment int
-}
-
-var defaultTextFont = TextFont{
-	face:        bitmapfont.Face,
-	xAdjustment: 4,
-	yAdjustment: 4,
-}
-
-func NewTextFont(face font.Face, xAdjustment int, yAdjustment int) TextFont {
-	return TextFont{
-		face,
-		xAdjustment,
-		yAdjustment,
-	}
+	// px, the size of the font when not set. LineHeight grows with the size
+	Size   *float64
+	Weight *FontWeight
+	Width  *sizeSeg
+	// splits a word that does not fit in the line with a hyphen
+	Hyphenate HyphenateFunc
+	// aligned in Width, or in the widest line
+	Align *TextAlign
+	// the lines after it are not drawn
+	MaxLines *int
+	// Ellipsis ends the last line with "…" when lines are cut by MaxLines
+	Overflow *OverflowType
+	// px, up to maxTextStroke, drawn around the glyphs
+	StrokeWidth *int
+	StrokeColor *color.Color
+	Shadow      *TextShadow
+	// gradient multiplied with the colors of the text, see ColorCodeVertical
+	Fill *[4]color.Color
+	// applied to the text without changing the layout
+	Opacity         *float64
+	Scale           *float64
+	Rotation        *float64 // radian
+	TransformOrigin *[2]float64
 }
 
 func mergeTextStyle(target TextStyle, styles []TextStyle) TextStyle {
@@ -57,9 +78,51 @@ func mergeTextStyle(target TextStyle, styles []TextStyle) TextStyle {
 		if styles[i].Font != nil {
 			target.Font = styles[i].Font
 		}
+		if styles[i].Size != nil {
+			target.Size = styles[i].Size
+		}
+		if styles[i].Weight != nil {
+			target.Weight = styles[i].Weight
+		}
 		if styles[i].Width != nil {
 			target.Width = styles[i].Width
 		}
+		if styles[i].Hyphenate != nil {
+			target.Hyphenate = styles[i].Hyphenate
+		}
+		if styles[i].Align != nil {
+			target.Align = styles[i].Align
+		}
+		if styles[i].MaxLines != nil {
+			target.MaxLines = styles[i].MaxLines
+		}
+		if styles[i].Overflow != nil {
+			target.Overflow = styles[i].Overflow
+		}
+		if styles[i].StrokeWidth != nil {
+			target.StrokeWidth = styles[i].StrokeWidth
+		}
+		if styles[i].StrokeColor != nil {
+			target.StrokeColor = styles[i].StrokeColor
+		}
+		if styles[i].Shadow != nil {
+			target.Shadow = styles[i].Shadow
+		}
+		if styles[i].Fill != nil {
+			target.Fill = styles[i].Fill
+		}
+		if styles[i].Opacity != nil {
+			target.Opacity = styles[i].Opacity
+		}
+		if styles[i].Scale != nil {
+			target.Scale = styles[i].Scale
+		}
+		if styles[i].Rotation != nil {
+			target.Rotation = styles[i].Rotation
+		}
+		if styles[i].TransformOrigin != nil {
+			target.TransformOrigin = styles[i].TransformOrigin
+		}
 	}
 	return target
 }
@@ -74,84 +137,100 @@ func getDefaultTextStyle() TextStyle {
 }
 
 func NewText(str string, styles ...TextStyle) Text {
+	return NewRichText([]TextSpan{{Text: str}}, styles...)
+}
+
+// NewRichText creates a Text of spans that each can have their own color, font and size.
+func NewRichText(spans []TextSpan, styles ...TextStyle) Text {
 	var style = mergeTextStyle(getDefaultTextStyle(), styles)
-	return &textComponent{str: str, size: nil, style: style}
+	return &textComponent{spans: spans, size: nil, style: style, reveal: noReveal}
+}
+
+// NewMarkupText creates a Text of the spans parsed from markup, see ParseMarkup.
+func NewMarkupText(markup string, styles ...TextStyle) Text {
+	return NewRichText(ParseMarkup(markup), styles...)
 }
 
 func (t Text) GetSize() image.Point {
+	if t.icons != iconGeneration {
+		t.icons = iconGeneration
+		t.size = nil
+	}
 	if t.size != nil {
 		return *t.size
 	}
-	if t.style.Width != nil {
-		var str = ""
-		var line = ""
-		var lineAdv fixed.Int26_6
-		maxWidthPx := calcSize(t.screenSize, *t.style.Width)
-		maxWidthFixed := fixed.I(maxWidthPx)
-		for _, _char := range t.str {
-			var char = string(_char)
-			adv, _ := t.style.Font.face.GlyphAdvance(_char)
-			if lineAdv+adv > maxWidthFixed {
-				if len(line) == 0 {
-					str += line + char + "\n"
-					line = ""
-					lineAdv = 0
-					continue
-				} else {
-					str += line + "\n"
-					line = char
-					lineAdv = adv
-					continue
-				}
-			}
-			line += char
-			lineAdv += adv
-		}
-		if len(line) > 0 {
-			str += line
-		}
-		t.str = str
-	}
-
-	var lineHeightPx = calcSize(t.screenSize, *t.style.LineHeight)
-	lines := strings.Split(t.str, "\n")
-	maxW := 0
-	for _, ln := range lines {
-		adv := font.MeasureString(t.style.Font.face, ln)
-		w := adv.Round()
-		if w > maxW {
-			maxW = w
+	var maxWidth = math.Inf(1)
+	if t.style.Width != nil || t.box.limit.X > 0 {
+		maxWidthPx := t.box.limit.X
+		if t.style.Width != nil {
+			maxWidthPx = limitLength(t.box.limit.X, calcSize(t.box, *t.style.Width))
 		}
+		maxWidth = float64(maxWidthPx)
 	}
-	if maxW > 0 {
-		maxW -= 1 // 既存挙動に合わせる
-	}
-	var lineCount = len(lines)
-	t.size = &image.Point{
-		X: maxW,
-		Y: lineCount * lineHeightPx,
-	}
+	var lineHeightPx = calcSize(t.box, *t.style.LineHeight)
+	t.layout = layoutText(t.spans, t.style, lineHeightPx, maxWidth)
+	t.effects.invalidate()
+	var size = t.layout.size()
+	t.size = &size
 	return *t.size
 }
 
+func (t Text) setLayoutBox(box layoutBox) {
+	if t.box != box {
+		t.size = nil
+	}
+	t.box = box
+	t.boxAssigned = true
+}
+
 func (t Text) Draw(screen *ebiten.Image, x, y int) {
-	t.screenSize = screen.Bounds().Size()
+	var transform = getStyleTransform(t.style.Opacity, t.style.Scale, t.style.Rotation, t.style.TransformOrigin)
+	t.transform.draw(screen, transform, func(target *ebiten.Image, dx, dy int) image.Rectangle {
+		t.draw(target, x+dx, y+dy)
+		return t.drawnArea
+	})
+}
+
+func (t Text) draw(screen *ebiten.Image, x, y int) {
+	var box = t.box
+	if !t.boxAssigned {
+		box = screenBox(screen)
+	}
+	if t.box != box {
+		t.size = nil
+	}
+	t.box = box
 	var size = t.GetSize()
 	t.drawnArea = image.Rect(x, y, x+size.X, y+size.Y)
-	var textFont = t.style.Font
-	var lineHeight = t.style.LineHeight
-	var lineHeightPx = calcSize(t.screenSize, *lineHeight)
-	x += textFont.xAdjustment
-	y += lineHeightPx - lineHeightPx/2 + textFont.yAdjustment
-	op := &ebiten.DrawImageOptions{}
-	op.GeoM.Translate(float64(x), float64(y))
-	op.ColorScale.ScaleWithColor(*t.style.Color)
-	text.DrawWithOptions(screen, t.str, text.FaceWithLineHeight(textFont.face, float64(lineHeightPx)), op)
+	t.drawLayout(screen, x, y)
+}
+
+// drawLayout draws the laid-out text with its effects at x, y.
+func (t Text) drawLayout(screen *ebiten.Image, x, y int) {
+	var styler = t.glyphStyler()
+	if hasTextEffects(t.style) {
+		var img, offset = t.effects.render(t.layout, t.layout.size(), t.style, styler)
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x+offset.X), float64(y+offset.Y))
+		screen.DrawImage(img, op)
+		return
+	}
+	t.layout.draw(screen, x, y, styler)
 }
 
 func (t Text) ChangeText(text string) {
-	t.str = text
+	t.SetSpans([]TextSpan{{Text: text}})
+}
+
+func (t Text) SetSpans(spans []TextSpan) {
+	t.spans = spans
 	t.size = nil
+	t.reveal = noReveal
+}
+
+// ChangeMarkup replaces the spans with the ones parsed from markup, see ParseMarkup.
+func (t Text) ChangeMarkup(markup string) {
+	t.SetSpans(ParseMarkup(markup))
 }
 
 func (t Text) IsFloating() bool {
@@ -163,5 +242,19 @@ func (t Text) Components() []Component {
 }
 
 func (t Text) Area() image.Rectangle {
-	return t.drawnArea
+	return t.transform.area(t.drawnArea)
+}
+
+func (t Text) untransformPoint(point image.Point) image.Point {
+	return t.transform.untransform(point, t.drawnArea)
+}
+
+func (t Text) containsPoint(point image.Point) bool {
+	return point.In(t.drawnArea)
+}
+
+// Dispose releases the offscreen layer of a transformed text and the image of its effects.
+func (t Text) Dispose() {
+	t.transform.dispose()
+	t.effects.dispose()
 }
@@ -0,0 +1,189 @@
+package game_ui
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// TextShadow is drawn below the text and its stroke.
+type TextShadow struct {
+	X, Y int // px
+	// px, up to maxShadowBlur
+	Blur  int
+	Color *color.Color
+}
+
+const maxTextStroke = 8
+
+var defaultStrokeColor color.Color = color.Black
+
+var strokeShaderSource = []byte(`//kage:unit pixels
+
+package main
+
+var Radius float
+var Color vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	alpha := 0.0
+	for y := 0; y < 17; y++ {
+		for x := 0; x < 17; x++ {
+			d := vec2(float(x)-8, float(y)-8)
+			// antialiased edge of the disk
+			cover := clamp(Radius+0.5-length(d), 0, 1)
+			alpha = max(alpha, imageSrc0At(srcPos+d).a*cover)
+		}
+	}
+	return Color * alpha
+}
+`)
+
+var strokeShader *ebiten.Shader
+
+func getStrokeShader() *ebiten.Shader {
+	if strokeShader == nil {
+		var shader, err = ebiten.NewShader(strokeShaderSource)
+		if err != nil {
+			panic(err)
+		}
+		strokeShader = shader
+	}
+	return strokeShader
+}
+
+// multiplyBlend multiplies the destination by the source.
+var multiplyBlend = ebiten.Blend{
+	BlendFactorSourceRGB:        ebiten.BlendFactorDestinationColor,
+	BlendFactorSourceAlpha:      ebiten.BlendFactorDestinationAlpha,
+	BlendFactorDestinationRGB:   ebiten.BlendFactorZero,
+	BlendFactorDestinationAlpha: ebiten.BlendFactorZero,
+	BlendOperationRGB:           ebiten.BlendOperationAdd,
+	BlendOperationAlpha:         ebiten.BlendOperationAdd,
+}
+
+func hasTextEffects(style TextStyle) bool {
+	return (style.StrokeWidth != nil && *style.StrokeWidth > 0) || style.Shadow != nil || style.Fill != nil
+}
+
+type textEffectCache struct {
+	image *ebiten.Image
+	// the glyphs, the outline and the shadow before they are drawn on image
+	layers [4]*ebiten.Image
+	// position of the image relative to the text
+	offset image.Point
+	valid  bool
+}
+
+// reuseImage returns *img cleared, or a new image when it is nil or not of size.
+func reuseImage(img **ebiten.Image, size image.Point) *ebiten.Image {
+	if *img != nil && (*img).Bounds().Size() != size {
+		(*img).Deallocate()
+		*img = nil
+	}
+	if *img == nil {
+		*img = ebiten.NewImage(size.X, size.Y)
+	} else {
+		(*img).Clear()
+	}
+	return *img
+}
+
+func (c *textEffectCache) render(layout textLayout, size image.Point, style TextStyle, styler glyphStyler) (*ebiten.Image, image.Point) {
+	if !c.valid {
+		c.valid = true
+		c.offset = c.renderTextEffects(layout, size, style, styler)
+	}
+	return c.image, c.offset
+}
+
+// invalidate redraws the effects on the next render, the images are kept for it.
+func (c *textEffectCache) invalidate() {
+	c.valid = false
+}
+
+func (c *textEffectCache) dispose() {
+	for _, img := range append(c.layers[:], c.image) {
+		if img != nil {
+			img.Deallocate()
+		}
+	}
+	c.image, c.layers = nil, [4]*ebiten.Image{}
+	c.valid = false
+}
+
+// strokeText draws the outline of src, radius px wide, on dst of the same size.
+func strokeText(dst, src *ebiten.Image, radius int, c color.Color) {
+	var size = src.Bounds().Size()
+	var r, g, b, a = c.RGBA()
+	op := &ebiten.DrawRectShaderOptions{}
+	op.Images[0] = src
+	op.Uniforms = map[string]any{
+		"Radius": float32(radius),
+		"Color":  []float32{float32(r) / 0xffff, float32(g) / 0xffff, float32(b) / 0xffff, float32(a) / 0xffff},
+	}
+	dst.DrawRectShader(size.X, size.Y, getStrokeShader(), op)
+}
+
+// renderTextEffects draws layout of size with the fill, the stroke and the shadow of style on the image of c
+// and returns the position of the image relative to the text.
+func (c *textEffectCache) renderTextEffects(layout textLayout, size image.Point, style TextStyle, styler glyphStyler) image.Point {
+	var stroke = 0
+	if style.StrokeWidth != nil {
+		stroke = min(max(*style.StrokeWidth, 0), maxTextStroke)
+	}
+	// glyphs may reach out of their lines
+	var overhang = 0.0
+	for _, run := range layout.runs {
+		overhang = max(overhang, run.size/2)
+	}
+	var margin = stroke + int(math.Ceil(overhang))
+	var bounds = image.Rect(-margin, -margin, size.X+margin, size.Y+margin)
+
+	var img = reuseImage(&c.layers[0], bounds.Size())
+	layout.draw(img, margin, margin, styler)
+	if style.Fill != nil {
+		// over the margin too so that no glyph is left out of the gradient
+		var vertices = gradientVertices(colorKey(style.Fill), float32(bounds.Dx()), float32(bounds.Dy()))
+		op := &ebiten.DrawTrianglesOptions{}
+		op.Blend = multiplyBlend
+		img.DrawTriangles(vertices, gradientIndices, emptySubImage, op)
+	}
+	if stroke > 0 {
+		var strokeColor = defaultStrokeColor
+		if style.StrokeColor != nil {
+			strokeColor = *style.StrokeColor
+		}
+		var outline = reuseImage(&c.layers[1], bounds.Size())
+		strokeText(outline, img, stroke, strokeColor)
+		outline.DrawImage(img, nil)
+		img = outline
+	}
+	var shadow = style.Shadow
+	if shadow == nil {
+		reuseImage(&c.image, bounds.Size()).DrawImage(img, nil)
+		return bounds.Min
+	}
+	var shadowColor = defaultShadowColor
+	if shadow.Color != nil {
+		shadowColor = *shadow.Color
+	}
+	var blur = min(max(shadow.Blur, 0), maxShadowBlur)
+	var shadowBounds = bounds.Add(image.Point{X: shadow.X, Y: shadow.Y}).Inset(-blur)
+	var all = bounds.Union(shadowBounds)
+
+	var silhouette = reuseImage(&c.layers[2], all.Size())
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(shadowBounds.Min.X-all.Min.X+blur), float64(shadowBounds.Min.Y-all.Min.Y+blur))
+	silhouette.DrawImage(img, op)
+	fillRoundedRect(silhouette, 0, 0, float32(all.Dx()), float32(all.Dy()), [4]int{}, shadowColor, ebiten.BlendSourceIn)
+	var result = reuseImage(&c.image, all.Size())
+	blurImageTo(result, reuseImage(&c.layers[3], all.Size()), silhouette, blur)
+
+	op = &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(bounds.Min.X-all.Min.X), float64(bounds.Min.Y-all.Min.Y))
+	result.DrawImage(img, op)
+	return all.Min
+}
@@ -0,0 +1,841 @@
+package game_ui
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/exp/textinput"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/rivo/uniseg"
+)
+
+const caretBlinkInterval = 530 // msec
+
+// clipboard is shared by the text inputs of the app, the system clipboard is not used.
+var clipboard = ""
+
+type TextInputStyle struct {
+	// the focusable box around the text, merged over the default box
+	Box  *ViewStyle
+	Text *TextStyle
+	// shown while the value is empty
+	Placeholder      *string
+	PlaceholderColor *color.Color
+	// characters, grapheme clusters
+	MaxLength *int
+	// drawn for every character instead of the value, e.g. '•'
+	Mask *rune
+	// Enter breaks the line, the lines wrap and scroll to the caret
+	Multiline *bool
+	// lines shown by a multi-line input
+	Rows           *int
+	CaretColor     *color.Color
+	SelectionColor *color.Color
+}
+
+func mergeTextInputStyle(target TextInputStyle, styles []TextInputStyle) TextInputStyle {
+	for i := range styles {
+		if styles[i].Box != nil {
+			target.Box = Ptr(mergeViewStyle(*target.Box, []ViewStyle{*styles[i].Box}))
+		}
+		if styles[i].Text != nil {
+			target.Text = Ptr(mergeTextStyle(*target.Text, []TextStyle{*styles[i].Text}))
+		}
+		if styles[i].Placeholder != nil {
+			target.Placeholder = styles[i].Placeholder
+		}
+		if styles[i].PlaceholderColor != nil {
+			target.PlaceholderColor = styles[i].PlaceholderColor
+		}
+		if styles[i].MaxLength != nil {
+			target.MaxLength = styles[i].MaxLength
+		}
+		if styles[i].Mask != nil {
+			target.Mask = styles[i].Mask
+		}
+		if styles[i].Multiline != nil {
+			target.Multiline = styles[i].Multiline
+		}
+		if styles[i].Rows != nil {
+			target.Rows = styles[i].Rows
+		}
+		if styles[i].CaretColor != nil {
+			target.CaretColor = styles[i].CaretColor
+		}
+		if styles[i].SelectionColor != nil {
+			target.SelectionColor = styles[i].SelectionColor
+		}
+	}
+	return target
+}
+
+func getDefaultTextInputStyle() TextInputStyle {
+	return TextInputStyle{
+		Box: &ViewStyle{
+			Width:           Px(160),
+			Padding:         Size2(Px(4), Px(6)),
+			BorderWidth:     Size1(Px(1)),
+			BorderColor:     ColorCode1(0xffffff66),
+			BackgroundColor: ColorCode1(0x00000088),
+			Radius:          Radius1(2),
+		},
+		Text:             &TextStyle{},
+		Placeholder:      Ptr(""),
+		PlaceholderColor: Color(0xffffff66),
+		Multiline:        Ptr(false),
+		Rows:             Ptr(3),
+		CaretColor:       Color(0xffffffff),
+		SelectionColor:   Color(0x3399ff88),
+	}
+}
+
+type textInputComponent struct {
+	view        View
+	body        *textInputBody
+	text        Text
+	placeholder Text
+	style       TextInputStyle
+	value       string
+	// bytes in value, the selection is between them and the caret is at caret
+	anchor, caret int
+	field         textinput.Field
+	// the value with the text being composed by the IME
+	shown       string
+	composition [2]int
+	shownCaret  int
+	// px the text is scrolled by in the box
+	scroll    image.Point
+	selecting bool
+	// a VirtualKeyboard types into the input, its caret is shown without the focus
+	typing bool
+	// time of the last edit, the caret blinks from it
+	edited int64
+	// the caret moved since the last update, e.g. by a VirtualKeyboard, and is scrolled to in the next one
+	caretMoved bool
+	now        int64
+	validate   func(value string) bool
+	change     func(value string)
+	submit     func(value string)
+}
+
+// TextInput is an editable text in a focusable box, with a caret, a selection, IME composition and an in-app clipboard.
+type TextInput = *textInputComponent
+
+// textInputBody is the content of the box of a TextInput, it draws the text, the selection and the caret.
+type textInputBody struct {
+	input     TextInput
+	box       layoutBox
+	drawnArea image.Rectangle
+}
+
+func NewTextInput(value string, styles ...TextInputStyle) TextInput {
+	var style = mergeTextInputStyle(getDefaultTextInputStyle(), styles)
+	var textStyle = *style.Text
+	if *style.Multiline && textStyle.Width == nil {
+		textStyle.Width = Pw(1)
+	}
+	var t = &textInputComponent{style: style}
+	t.text = NewText("", textStyle)
+	t.placeholder = NewText(*style.Placeholder, textStyle, TextStyle{Color: style.PlaceholderColor})
+	t.body = &textInputBody{input: t}
+	t.view = NewView([]Component{t.body}, *style.Box)
+	t.view.SetFocusable(true)
+	t.view.capturedKeys = []ebiten.Key{ebiten.KeyArrowLeft, ebiten.KeyArrowRight, ebiten.KeyEnter, ebiten.KeySpace}
+	if *style.Multiline {
+		t.view.capturedKeys = append(t.view.capturedKeys, ebiten.KeyArrowUp, ebiten.KeyArrowDown)
+	}
+	t.SetValue(value)
+	return t
+}
+
+// View returns the box of the input, e.g. to focus it with the FocusManager.
+func (t TextInput) View() View {
+	return t.view
+}
+
+func (t TextInput) Value() string {
+	return t.value
+}
+
+// SetValue replaces the value without the validator and puts the caret at the end.
+func (t TextInput) SetValue(value string) {
+	t.value = t.clean(value)
+	t.anchor, t.caret = len(t.value), len(t.value)
+	t.caretMoved = true
+	t.syncField()
+	t.refresh()
+}
+
+// Selection returns the selected bytes of the value, they are the same when nothing is selected.
+func (t TextInput) Selection() (int, int) {
+	return min(t.anchor, t.caret), max(t.anchor, t.caret)
+}
+
+// SetSelection selects the bytes of the value from start to end, the caret goes to end.
+func (t TextInput) SetSelection(start, end int) {
+	t.anchor = clusterBoundary(t.value, min(max(start, 0), len(t.value)))
+	t.caret = clusterBoundary(t.value, min(max(end, 0), len(t.value)))
+	t.caretMoved = true
+	t.syncField()
+	t.refresh()
+}
+
+func (t TextInput) SelectAll() {
+	t.SetSelection(0, len(t.value))
+}
+
+// SetValidator rejects the edits that make validate return false, e.g. to accept only digits.
+func (t TextInput) SetValidator(validate func(value string) bool) {
+	t.validate = validate
+}
+
+func (t TextInput) OnChange(handler func(value string)) {
+	t.change = handler
+}
+
+// OnSubmit is called when Enter is pressed in a single-line input.
+func (t TextInput) OnSubmit(handler func(value string)) {
+	t.submit = handler
+}
+
+// Insert replaces the selection with str, cut to the MaxLength.
+func (t TextInput) Insert(str string) {
+	str = t.clean(str)
+	var start, end = t.Selection()
+	if t.style.MaxLength != nil {
+		var room = *t.style.MaxLength - uniseg.GraphemeClusterCount(t.value[:start]+t.value[end:])
+		str = firstClusters(str, max(room, 0))
+	}
+	if str == "" && start == end {
+		return
+	}
+	t.edit(t.value[:start]+str+t.value[end:], start+len(str))
+}
+
+// Backspace deletes the selection, or the character before the caret.
+func (t TextInput) Backspace() {
+	var start, end = t.Selection()
+	if start == end {
+		start = prevCluster(t.value, start)
+	}
+	t.edit(t.value[:start]+t.value[end:], start)
+}
+
+// Submit calls the handler of OnSubmit with the value.
+func (t TextInput) Submit() {
+	if t.submit != nil {
+		t.submit(t.value)
+	}
+}
+
+// clean removes the line breaks of a single-line input.
+func (t TextInput) clean(str string) string {
+	str = strings.ReplaceAll(str, "\r\n", "\n")
+	if *t.style.Multiline {
+		return str
+	}
+	return strings.NewReplacer("\n", "", "\r", "").Replace(str)
+}
+
+// edit changes the value when the validator accepts it and puts the caret at caret.
+func (t TextInput) edit(value string, caret int) {
+	if value != t.value && t.validate != nil && !t.validate(value) {
+		return
+	}
+	var changed = value != t.value
+	t.value = value
+	t.anchor, t.caret = caret, caret
+	t.edited = t.now
+	t.caretMoved = true
+	t.syncField()
+	t.refresh()
+	if changed && t.change != nil {
+		t.change(value)
+	}
+}
+
+// move puts the caret at offset, the selection grows from the anchor when selecting.
+func (t TextInput) move(offset int, selecting bool) {
+	t.caret = offset
+	if !selecting {
+		t.anchor = offset
+	}
+	t.edited = t.now
+	t.caretMoved = true
+	t.syncField()
+	t.refresh()
+}
+
+// syncField passes the value and the selection to the IME when they changed.
+func (t TextInput) syncField() {
+	var start, end = t.Selection()
+	var fieldStart, fieldEnd = t.field.Selection()
+	if t.field.Text() != t.value || fieldStart != start || fieldEnd != end {
+		t.field.SetTextAndSelection(t.value, start, end)
+	}
+}
+
+// refresh updates the text shown from the value and the composition.
+func (t TextInput) refresh() {
+	t.shown, t.shownCaret = t.value, t.caret
+	t.composition = [2]int{}
+	if length := t.field.UncommittedTextLengthInBytes(); length > 0 {
+		var start, _ = t.field.Selection()
+		t.shown = t.field.TextForRendering()
+		t.composition = [2]int{start, start + length}
+		t.shownCaret = start + length
+		if caret, _, ok := t.field.CompositionSelection(); ok {
+			t.shownCaret = start + caret
+		}
+	}
+	var display = t.shown
+	if t.style.Mask != nil {
+		display = strings.Repeat(string(*t.style.Mask), uniseg.GraphemeClusterCount(t.shown))
+	}
+	if display != t.text.spans[0].Text {
+		t.text.ChangeText(display)
+	}
+}
+
+// displayOffset returns the offset in the text drawn of offset in the shown text.
+func (t TextInput) displayOffset(offset int) int {
+	if t.style.Mask == nil {
+		return offset
+	}
+	return uniseg.GraphemeClusterCount(t.shown[:offset]) * utf8.RuneLen(*t.style.Mask)
+}
+
+// shownOffset returns the offset in the shown text of offset in the text drawn.
+func (t TextInput) shownOffset(offset int) int {
+	if t.style.Mask == nil {
+		return offset
+	}
+	var count = offset / utf8.RuneLen(*t.style.Mask)
+	return len(firstClusters(t.shown, count))
+}
+
+// caretPosition returns the position of the caret at offset of the shown text, from the top left of the text.
+func (t TextInput) caretPosition(offset int) (float64, float64, int) {
+	t.text.GetSize()
+	return t.text.layout.caretPosition(t.displayOffset(offset))
+}
+
+// offsetAt returns the offset of the value nearest to the point px from the top left of the text.
+func (t TextInput) offsetAt(x, y float64) int {
+	t.text.GetSize()
+	return t.shownOffset(t.text.layout.offsetAt(x, y))
+}
+
+func (t TextInput) copySelection() bool {
+	var start, end = t.Selection()
+	if start == end || t.style.Mask != nil {
+		return false
+	}
+	clipboard = t.value[start:end]
+	return true
+}
+
+func (t TextInput) handleKeys() {
+	var shift = ebiten.IsKeyPressed(ebiten.KeyShift)
+	var command = ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+	var start, end = t.Selection()
+	var pressed = func(keys ...ebiten.Key) bool {
+		return keyFired(keys, true)
+	}
+	switch {
+	case command && pressed(ebiten.KeyA):
+		t.SelectAll()
+	case command && pressed(ebiten.KeyC):
+		t.copySelection()
+	case command && pressed(ebiten.KeyX):
+		if t.copySelection() {
+			t.Insert("")
+		}
+	case command && pressed(ebiten.KeyV):
+		t.Insert(clipboard)
+	case pressed(ebiten.KeyArrowLeft):
+		if start != end && !shift {
+			t.move(start, false)
+		} else if command {
+			t.move(prevWord(t.value, t.caret), shift)
+		} else {
+			t.move(prevCluster(t.value, t.caret), shift)
+		}
+	case pressed(ebiten.KeyArrowRight):
+		if start != end && !shift {
+			t.move(end, false)
+		} else if command {
+			t.move(nextWord(t.value, t.caret), shift)
+		} else {
+			t.move(nextCluster(t.value, t.caret), shift)
+		}
+	case *t.style.Multiline && pressed(ebiten.KeyArrowUp, ebiten.KeyArrowDown):
+		var x, y, height = t.caretPosition(t.caret)
+		if ebiten.IsKeyPressed(ebiten.KeyArrowUp) {
+			y -= 1
+		} else {
+			y += float64(height)
+		}
+		t.move(t.offsetAt(x, y), shift)
+	case pressed(ebiten.KeyHome):
+		var _, y, _ = t.caretPosition(t.caret)
+		if command {
+			t.move(0, shift)
+		} else {
+			t.move(t.offsetAt(0, y), shift)
+		}
+	case pressed(ebiten.KeyEnd):
+		var _, y, _ = t.caretPosition(t.caret)
+		if command {
+			t.move(len(t.value), shift)
+		} else {
+			t.move(t.offsetAt(1<<20, y), shift)
+		}
+	case pressed(ebiten.KeyBackspace):
+		if start == end && command {
+			t.edit(t.value[:prevWord(t.value, start)]+t.value[end:], prevWord(t.value, start))
+		} else {
+			t.Backspace()
+		}
+	case pressed(ebiten.KeyDelete):
+		if start == end && command {
+			end = nextWord(t.value, end)
+		} else if start == end {
+			end = nextCluster(t.value, end)
+		}
+		t.edit(t.value[:start]+t.value[end:], start)
+	case pressed(ebiten.KeyEnter, ebiten.KeyNumpadEnter):
+		if *t.style.Multiline {
+			t.Insert("\n")
+		} else {
+			t.Submit()
+		}
+	}
+}
+
+// commitField applies the text committed by the IME to the value.
+func (t TextInput) commitField() {
+	var text = t.field.Text()
+	if text == t.value {
+		return
+	}
+	var start, end = t.Selection()
+	var kept = len(t.value) - (end - start)
+	if len(text) >= kept && strings.HasPrefix(text, t.value[:start]) && strings.HasSuffix(text, t.value[end:]) {
+		t.Insert(text[start : len(text)-len(t.value)+end])
+	} else {
+		var _, caret = t.field.Selection()
+		t.edit(t.clean(text), min(caret, len(t.clean(text))))
+	}
+	// the value may have been cut or rejected
+	t.syncField()
+}
+
+// caretBounds returns the area of the caret on the screen, where the IME shows its window.
+func (t TextInput) caretBounds() image.Rectangle {
+	var x, y, height = t.caretPosition(t.shownCaret)
+	var origin = t.body.drawnArea.Min.Sub(t.scroll)
+	var top = origin.Add(image.Point{X: int(x), Y: int(y)})
+	return image.Rectangle{Min: top, Max: top.Add(image.Point{X: 1, Y: max(height, 1)})}
+}
+
+// scrollToCaret scrolls the text so that the caret is in the box.
+func (t TextInput) scrollToCaret() {
+	var size = t.body.GetSize()
+	var textSize = t.text.GetSize()
+	var x, y, height = t.caretPosition(t.shownCaret)
+	var caret = image.Rect(int(x), int(y), int(x)+1, int(y)+height)
+	if caret.Max.X > t.scroll.X+size.X {
+		t.scroll.X = caret.Max.X - size.X
+	}
+	if caret.Min.X < t.scroll.X {
+		t.scroll.X = caret.Min.X
+	}
+	if caret.Max.Y > t.scroll.Y+size.Y {
+		t.scroll.Y = caret.Max.Y - size.Y
+	}
+	if caret.Min.Y < t.scroll.Y {
+		t.scroll.Y = caret.Min.Y
+	}
+	t.clampScroll(size, textSize)
+}
+
+func (t TextInput) clampScroll(size, textSize image.Point) {
+	t.scroll.X = max(min(t.scroll.X, textSize.X+1-size.X), 0)
+	t.scroll.Y = max(min(t.scroll.Y, textSize.Y-size.Y), 0)
+}
+
+func (t TextInput) update(ctx *updateContext, path []Component, focus FocusManager) {
+	t.now = ctx.now
+	var hovered = containsView(pathViews(path), t.view)
+	if hovered && ctx.justPressed {
+		focus.Focus(t.view)
+	}
+	if !t.view.focused {
+		if t.field.IsFocused() {
+			t.field.Blur()
+		}
+		t.selecting = false
+		t.refresh()
+		if t.caretMoved {
+			t.scrollToCaret()
+			t.caretMoved = false
+		}
+		return
+	}
+	if !t.field.IsFocused() {
+		t.field.Focus()
+		t.edited = ctx.now
+	}
+
+	var point = ctx.pointer.Sub(t.body.drawnArea.Min).Add(t.scroll)
+	if hovered && ctx.justPressed {
+		t.move(t.offsetAt(float64(point.X), float64(point.Y)), ebiten.IsKeyPressed(ebiten.KeyShift))
+		t.selecting = true
+	} else if t.selecting && ctx.pressed {
+		var caret = t.offsetAt(float64(point.X), float64(point.Y))
+		if caret != t.caret {
+			t.move(caret, true)
+		}
+	}
+	if !ctx.pressed {
+		t.selecting = false
+	}
+	if hovered && *t.style.Multiline && ctx.wheel[1] != 0 {
+		t.scroll.Y -= int(ctx.wheel[1] * wheelScrollSpeed)
+	}
+
+	// the keys pressed while composing belong to the IME, also the one that commits the composition
+	var composing = t.field.UncommittedTextLengthInBytes() > 0
+	var handled, err = t.field.HandleInputWithBounds(t.caretBounds())
+	if err == nil && handled {
+		t.commitField()
+	}
+	// typed characters are inserted first, keys pressed in the same tick act after them
+	if !composing && t.field.UncommittedTextLengthInBytes() == 0 {
+		t.handleKeys()
+	}
+	t.refresh()
+	// the wheel scrolls away from the caret until it moves
+	if handled || t.caretMoved || t.edited == ctx.now {
+		t.scrollToCaret()
+		t.caretMoved = false
+	} else {
+		t.clampScroll(t.body.GetSize(), t.text.GetSize())
+	}
+}
+
+// updateTextInputs passes the input to the text inputs under components.
+func updateTextInputs(components []Component, path []Component, ctx *updateContext, focus FocusManager) {
+	walkComponents(components, 0, func(component Component, depth int) {
+		if input, ok := component.(TextInput); ok {
+			input.update(ctx, path, focus)
+		}
+	})
+}
+
+func (t TextInput) GetSize() image.Point {
+	return t.view.GetSize()
+}
+
+func (t TextInput) Draw(screen *ebiten.Image, x, y int) {
+	t.view.Draw(screen, x, y)
+}
+
+func (t TextInput) IsFloating() bool {
+	return t.view.IsFloating()
+}
+
+func (t TextInput) Components() []Component {
+	return []Component{t.view}
+}
+
+func (t TextInput) Area() image.Rectangle {
+	return t.view.Area()
+}
+
+func (t TextInput) setLayoutBox(box layoutBox) {
+	t.view.setLayoutBox(box)
+}
+
+func (t TextInput) measure() image.Point {
+	return t.view.measure()
+}
+
+func (t TextInput) flexFactors() (float32, float32) {
+	return t.view.flexFactors()
+}
+
+func (t TextInput) setFixedSize(size *image.Point) {
+	t.view.setFixedSize(size)
+}
+
+func (t TextInput) fillsAxis(horizontal bool) bool {
+	return t.view.fillsAxis(horizontal)
+}
+
+func (t TextInput) positionStyle() ([4]*sizeSeg, AnchorType, int) {
+	return t.view.positionStyle()
+}
+
+func (t TextInput) Dispose() {
+	t.field.Blur()
+	t.view.Dispose()
+	t.text.Dispose()
+	t.placeholder.Dispose()
+}
+
+func (b *textInputBody) setLayoutBox(box layoutBox) {
+	b.box = box
+	// a single line does not wrap
+	var textBox = layoutBox{screen: box.screen, parent: box.parent}
+	b.input.text.setLayoutBox(textBox)
+	b.input.placeholder.setLayoutBox(textBox)
+}
+
+// GetSize returns the width offered by the box, and the height of one line or of the rows.
+func (b *textInputBody) GetSize() image.Point {
+	var t = b.input
+	t.text.GetSize()
+	var lineHeight = t.text.layout.lines[0].height()
+	if *t.style.Multiline {
+		return image.Point{X: b.box.parent.X, Y: lineHeight * max(*t.style.Rows, 1)}
+	}
+	return image.Point{X: b.box.parent.X, Y: lineHeight}
+}
+
+func (b *textInputBody) Draw(screen *ebiten.Image, x, y int) {
+	var t = b.input
+	var size = b.GetSize()
+	b.drawnArea = image.Rect(x, y, x+size.X, y+size.Y)
+	var clip = b.drawnArea.Intersect(screen.Bounds())
+	if clip.Empty() {
+		return
+	}
+	var target = screen.SubImage(clip).(*ebiten.Image)
+	var origin = b.drawnArea.Min.Sub(t.scroll)
+	var focused = t.view.focused || t.typing
+
+	if start, end := t.Selection(); focused && start != end && t.composition[0] == t.composition[1] {
+		var c = *t.style.SelectionColor
+		for _, rect := range t.text.layout.rangeRects(t.displayOffset(start), t.displayOffset(end)) {
+			rect = rect.Add(origin)
+			vector.DrawFilledRect(target, float32(rect.Min.X), float32(rect.Min.Y), float32(rect.Dx()), float32(rect.Dy()), c, false)
+		}
+	}
+	if t.shown == "" {
+		t.placeholder.GetSize()
+		t.placeholder.drawLayout(target, origin.X, origin.Y)
+	} else {
+		t.text.GetSize()
+		t.text.drawLayout(target, origin.X, origin.Y)
+	}
+	if t.composition[0] != t.composition[1] {
+		// the text being composed is underlined
+		var c = *t.text.style.Color
+		for _, rect := range t.text.layout.rangeRects(t.displayOffset(t.composition[0]), t.displayOffset(t.composition[1])) {
+			rect = rect.Add(origin)
+			vector.DrawFilledRect(target, float32(rect.Min.X), float32(rect.Max.Y-1), float32(rect.Dx()), 1, c, false)
+		}
+	}
+	if focused && (t.now-t.edited)/caretBlinkInterval%2 == 0 {
+		var caretX, caretY, height = t.caretPosition(t.shownCaret)
+		var caret = image.Point{X: int(caretX), Y: int(caretY)}.Add(origin)
+		vector.DrawFilledRect(target, float32(caret.X), float32(caret.Y), 1, float32(height), *t.style.CaretColor, false)
+	}
+}
+
+func (b *textInputBody) IsFloating() bool {
+	return false
+}
+
+func (b *textInputBody) Components() []Component {
+	return []Component{}
+}
+
+func (b *textInputBody) Area() image.Rectangle {
+	return b.drawnArea
+}
+
+// lineTop returns the px from the top of the text to the line at index.
+func (l textLayout) lineTop(index int) int {
+	var top = 0
+	for _, line := range l.lines[:index] {
+		top += line.height()
+	}
+	return top
+}
+
+// lineAt returns the index of the line offset is in.
+func (l textLayout) lineAt(offset int) int {
+	var index = 0
+	for i, line := range l.lines {
+		if line.start <= offset {
+			index = i
+		}
+	}
+	return index
+}
+
+// lineEnd returns the offset after the last glyph of the line at index.
+func (l textLayout) lineEnd(index int) int {
+	var line = l.lines[index]
+	var end = line.start
+	for _, glyph := range line.glyphs {
+		if index+1 < len(l.lines) && glyph.offset >= l.lines[index+1].start {
+			// the hyphen of a broken word
+			continue
+		}
+		if glyph.image != nil {
+			end = glyph.offset + len("\uFFFC")
+		} else {
+			end = glyph.offset + len(glyph.text)
+		}
+	}
+	return end
+}
+
+// caretX returns the px from the left of the text to the caret before offset in the line at index.
+func (l textLayout) caretX(index int, offset int) float64 {
+	var line = l.lines[index]
+	for _, glyph := range line.glyphs {
+		if glyph.offset >= offset {
+			return line.x + glyph.x
+		}
+	}
+	return line.x + line.end
+}
+
+// caretPosition returns the px from the top left of the text to the caret before offset, and the height of its line.
+func (l textLayout) caretPosition(offset int) (float64, float64, int) {
+	var index = l.lineAt(offset)
+	return l.caretX(index, offset), float64(l.lineTop(index)), l.lines[index].height()
+}
+
+// offsetAt returns the offset of the caret nearest to the point px from the top left of the text.
+func (l textLayout) offsetAt(x, y float64) int {
+	var index = 0
+	for top := 0; index < len(l.lines)-1; index++ {
+		top += l.lines[index].height()
+		if y < float64(top) {
+			break
+		}
+	}
+	var line = l.lines[index]
+	var end = l.lineEnd(index)
+	for _, glyph := range line.glyphs {
+		if glyph.offset >= end {
+			break
+		}
+		if x < line.x+glyph.x+glyph.advance/2 {
+			return glyph.offset
+		}
+	}
+	return end
+}
+
+// rangeRects returns the areas of the lines covered by the text from start to end.
+func (l textLayout) rangeRects(start, end int) []image.Rectangle {
+	var rects = []image.Rectangle{}
+	var top = 0
+	for i, line := range l.lines {
+		var lineEnd = l.lineEnd(i)
+		if line.start <= end && lineEnd >= start {
+			var left = l.caretX(i, max(start, line.start))
+			var right = l.caretX(i, min(end, lineEnd))
+			if end > lineEnd && i+1 < len(l.lines) {
+				// the line break is selected too
+				right = max(right, line.x+line.end) + 4
+			}
+			if right > left {
+				rects = append(rects, image.Rect(int(left), top, int(right), top+line.height()))
+			}
+		}
+		top += line.height()
+	}
+	return rects
+}
+
+// clusterBoundary returns the start of the grapheme cluster offset is in.
+func clusterBoundary(str string, offset int) int {
+	var boundary = 0
+	var state = -1
+	for rest := str; len(rest) > 0; {
+		var cluster string
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		if boundary+len(cluster) > offset {
+			break
+		}
+		boundary += len(cluster)
+	}
+	return boundary
+}
+
+func prevCluster(str string, offset int) int {
+	if offset <= 0 {
+		return 0
+	}
+	return clusterBoundary(str, offset-1)
+}
+
+func nextCluster(str string, offset int) int {
+	if offset >= len(str) {
+		return len(str)
+	}
+	var cluster, _, _, _ = uniseg.FirstGraphemeClusterInString(str[offset:], -1)
+	return offset + len(cluster)
+}
+
+// firstClusters returns the first count grapheme clusters of str.
+func firstClusters(str string, count int) string {
+	var end = 0
+	var state = -1
+	for rest := str; len(rest) > 0 && count > 0; count-- {
+		var cluster string
+		cluster, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+		end += len(cluster)
+	}
+	return str[:end]
+}
+
+// prevWord returns the start of the word before offset, after the spaces before it.
+func prevWord(str string, offset int) int {
+	for offset > 0 {
+		var r, size = utf8.DecodeLastRuneInString(str[:offset])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		offset -= size
+	}
+	for offset > 0 {
+		var r, _ = utf8.DecodeLastRuneInString(str[:offset])
+		if unicode.IsSpace(r) {
+			break
+		}
+		offset = prevCluster(str, offset)
+	}
+	return offset
+}
+
+// nextWord returns the end of the word after offset, with the spaces after it.
+func nextWord(str string, offset int) int {
+	for offset < len(str) {
+		var r, _ = utf8.DecodeRuneInString(str[offset:])
+		if unicode.IsSpace(r) {
+			break
+		}
+		offset = nextCluster(str, offset)
+	}
+	for offset < len(str) {
+		var r, size = utf8.DecodeRuneInString(str[offset:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,155 @@
+package game_ui
+
+import (
+	"image"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// transform is applied around origin, a point relative to the area of a component.
+type transform struct {
+	opacity   float64
+	translate [2]float64
+	scale     float64
+	rotation  float64 // radian
+	origin    [2]float64
+}
+
+var identityTransform = transform{opacity: 1, scale: 1, origin: [2]float64{0.5, 0.5}}
+
+// then returns t followed by next, the origin of t is kept.
+func (t transform) then(next transform) transform {
+	t.opacity *= next.opacity
+	t.translate[0] += next.translate[0]
+	t.translate[1] += next.translate[1]
+	t.scale *= next.scale
+	t.rotation += next.rotation
+	return t
+}
+
+// translatesOnly reports whether t can be drawn without an offscreen layer.
+func (t transform) translatesOnly() bool {
+	return t.opacity == 1 && t.scale == 1 && t.rotation == 0
+}
+
+func (t transform) geoM(area image.Rectangle) ebiten.GeoM {
+	var originX = float64(area.Min.X) + float64(area.Dx())*t.origin[0]
+	var originY = float64(area.Min.Y) + float64(area.Dy())*t.origin[1]
+	var m = ebiten.GeoM{}
+	m.Translate(-originX, -originY)
+	m.Scale(t.scale, t.scale)
+	m.Rotate(t.rotation)
+	m.Translate(originX+t.translate[0], originY+t.translate[1])
+	return m
+}
+
+// bounds returns the bounding box of area after the transform.
+func (t transform) bounds(area image.Rectangle) image.Rectangle {
+	var m = t.geoM(area)
+	var minX, minY = math.Inf(1), math.Inf(1)
+	var maxX, maxY = math.Inf(-1), math.Inf(-1)
+	for _, corner := range []image.Point{area.Min, {X: area.Max.X, Y: area.Min.Y}, area.Max, {X: area.Min.X, Y: area.Max.Y}} {
+		var x, y = m.Apply(float64(corner.X), float64(corner.Y))
+		minX, minY = math.Min(minX, x), math.Min(minY, y)
+		maxX, maxY = math.Max(maxX, x), math.Max(maxY, y)
+	}
+	return image.Rect(int(math.Floor(minX)), int(math.Floor(minY)), int(math.Ceil(maxX)), int(math.Ceil(maxY)))
+}
+
+// untransform maps a point on the screen back to the coordinates the component was drawn in.
+func (t transform) untransform(point image.Point, area image.Rectangle) image.Point {
+	var m = t.geoM(area)
+	if !m.IsInvertible() {
+		return image.Point{X: math.MinInt32, Y: math.MinInt32}
+	}
+	m.Invert()
+	var x, y = m.Apply(float64(point.X), float64(point.Y))
+	return image.Point{X: int(math.Floor(x)), Y: int(math.Floor(y))}
+}
+
+// screenLayer returns layer cleared, or a new image when layer is nil or not the size of screen.
+func screenLayer(layer *ebiten.Image, screen *ebiten.Image) *ebiten.Image {
+	var size = screen.Bounds().Size()
+	if layer != nil && layer.Bounds().Size() != size {
+		layer.Deallocate()
+		layer = nil
+	}
+	if layer == nil {
+		layer = ebiten.NewImage(size.X, size.Y)
+	}
+	layer.Clear()
+	return layer
+}
+
+// drawTransformed draws layer, which holds a component drawn in area, to screen with t applied.
+func drawTransformed(screen, layer *ebiten.Image, t transform, area image.Rectangle) {
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM = t.geoM(area)
+	op.ColorScale.ScaleAlpha(float32(t.opacity))
+	if t.scale != 1 || t.rotation != 0 {
+		op.Filter = ebiten.FilterLinear
+	}
+	screen.DrawImage(layer, op)
+}
+
+func getStyleTransform(opacity, scale, rotation *float64, origin *[2]float64) transform {
+	var t = identityTransform
+	if opacity != nil {
+		t.opacity = *opacity
+	}
+	if scale != nil {
+		t.scale = *scale
+	}
+	if rotation != nil {
+		t.rotation = *rotation
+	}
+	if origin != nil {
+		t.origin = *origin
+	}
+	return t
+}
+
+// transformState draws a component through an offscreen layer while its transform needs one.
+type transformState struct {
+	layer *ebiten.Image
+	drawn transform
+	// whether the last draw went through the layer
+	active bool
+}
+
+// draw calls draw, which returns the area it has drawn, on screen or on the layer and applies t.
+func (s *transformState) draw(screen *ebiten.Image, t transform, draw func(target *ebiten.Image, dx, dy int) image.Rectangle) {
+	if t.translatesOnly() {
+		s.active = false
+		draw(screen, int(math.Round(t.translate[0])), int(math.Round(t.translate[1])))
+		return
+	}
+	s.layer = screenLayer(s.layer, screen)
+	var area = draw(s.layer, 0, 0)
+	s.drawn = t
+	s.active = true
+	drawTransformed(screen, s.layer, t, area)
+}
+
+// area returns the bounding box of area as it was drawn.
+func (s *transformState) area(area image.Rectangle) image.Rectangle {
+	if !s.active {
+		return area
+	}
+	return s.drawn.bounds(area)
+}
+
+func (s *transformState) untransform(point image.Point, area image.Rectangle) image.Point {
+	if !s.active {
+		return point
+	}
+	return s.drawn.untransform(point, area)
+}
+
+func (s *transformState) dispose() {
+	if s.layer != nil {
+		s.layer.Deallocate()
+		s.layer = nil
+	}
+}
@@ -0,0 +1,193 @@
+package game_ui
+
+import (
+	"image/color"
+	"math"
+)
+
+// Transition animates the colors, padding, margin, radius, size, opacity, scale and rotation of a View
+// whenever its resolved style changes. The Transition of the new style is used.
+type Transition struct {
+	Duration int64 // msec
+	Delay    int64 // msec
+	Easing   EasingFunc
+}
+
+// transitionValues are the animatable values of a resolved style in px.
+type transitionValues struct {
+	backgroundColor, borderColor [4]color.RGBA64
+	padding, margin              [4]int
+	radius                       [4]int
+	width, height                int
+	hasWidth, hasHeight          bool
+	widthUnit, heightUnit        sizeType
+	opacity, scale, rotation     float64
+}
+
+type transitionState struct {
+	initialized bool
+	active      bool
+	transition  Transition
+	start       int64
+	// the resolved style the values are compared with, against the box of the update
+	style    ViewStyle
+	from, to transitionValues
+	current  transitionValues
+	// whether Width and Height are animated, a size changing its unit or Fill snaps to the new size
+	width, height bool
+	// style merged over the resolved style while active
+	overrides ViewStyle
+}
+
+func lengthUnit(size *sizeSeg) sizeType {
+	if size == nil {
+		return auto
+	}
+	return size.t
+}
+
+// animatesLength reports whether a Width or Height is animated between from and to.
+func animatesLength(from, to int, fromUnit, toUnit sizeType) bool {
+	return from != to && fromUnit == toUnit && toUnit != auto && toUnit != fill
+}
+
+func (v View) getTransitionValues(style ViewStyle) transitionValues {
+	var values = transitionValues{
+		backgroundColor: colorKey(style.BackgroundColor),
+		borderColor:     colorKey(style.BorderColor),
+	}
+	if style.Padding != nil {
+		values.padding[0], values.padding[1], values.padding[2], values.padding[3] = getSizePx(v.box, *style.Padding)
+	}
+	if style.Margin != nil {
+		values.margin[0], values.margin[1], values.margin[2], values.margin[3] = getSizePx(v.box, *style.Margin)
+	}
+	if style.Radius != nil {
+		values.radius = *style.Radius
+	}
+	var t = getStyleTransform(style.Opacity, style.Scale, style.Rotation, nil)
+	values.opacity, values.scale, values.rotation = t.opacity, t.scale, t.rotation
+	values.width, values.hasWidth = calcLength(v.box, style.Width, true)
+	values.height, values.hasHeight = calcLength(v.box, style.Height, false)
+	values.widthUnit, values.heightUnit = lengthUnit(style.Width), lengthUnit(style.Height)
+	return values
+}
+
+func lerpInt(from, to int, rate float64) int {
+	return from + int(math.Round(float64(to-from)*rate))
+}
+
+// lerpLength keeps a length from overshooting below zero with an easing like EaseOutBack.
+func lerpLength(from, to int, rate float64) int {
+	return max(lerpInt(from, to, rate), min(from, to, 0))
+}
+
+func lerpColor(from, to color.RGBA64, rate float64) color.RGBA64 {
+	var lerp = func(a, b uint16) uint16 {
+		return uint16(min(max(float64(a)+(float64(b)-float64(a))*rate, 0), 0xffff))
+	}
+	return color.RGBA64{R: lerp(from.R, to.R), G: lerp(from.G, to.G), B: lerp(from.B, to.B), A: lerp(from.A, to.A)}
+}
+
+func lerpTransitionValues(from, to transitionValues, rate float64) transitionValues {
+	var values = to
+	for i := range 4 {
+		values.backgroundColor[i] = lerpColor(from.backgroundColor[i], to.backgroundColor[i], rate)
+		values.borderColor[i] = lerpColor(from.borderColor[i], to.borderColor[i], rate)
+		values.padding[i] = lerpLength(from.padding[i], to.padding[i], rate)
+		values.margin[i] = lerpLength(from.margin[i], to.margin[i], rate)
+		values.radius[i] = lerpLength(from.radius[i], to.radius[i], rate)
+	}
+	values.opacity = min(max(from.opacity+(to.opacity-from.opacity)*rate, 0), 1)
+	values.scale = from.scale + (to.scale-from.scale)*rate
+	values.rotation = from.rotation + (to.rotation-from.rotation)*rate
+	if animatesLength(from.width, to.width, from.widthUnit, to.widthUnit) {
+		values.width = lerpLength(from.width, to.width, rate)
+	}
+	if animatesLength(from.height, to.height, from.heightUnit, to.heightUnit) {
+		values.height = lerpLength(from.height, to.height, rate)
+	}
+	return values
+}
+
+// transitionOverrides returns the style of values, the sizes are only set when they are animated.
+func transitionOverrides(values transitionValues, width, height bool) ViewStyle {
+	var colors = func(keys [4]color.RGBA64) *[4]color.Color {
+		return &[4]color.Color{keys[0], keys[1], keys[2], keys[3]}
+	}
+	var sizes = func(px [4]int) *[4]sizeSeg {
+		return &[4]sizeSeg{*Px(px[0]), *Px(px[1]), *Px(px[2]), *Px(px[3])}
+	}
+	var radius = values.radius
+	var style = ViewStyle{
+		BackgroundColor: colors(values.backgroundColor),
+		BorderColor:     colors(values.borderColor),
+		Padding:         sizes(values.padding),
+		Margin:          sizes(values.margin),
+		Radius:          &radius,
+		Opacity:         Ptr(values.opacity),
+		Scale:           Ptr(values.scale),
+		Rotation:        Ptr(values.rotation),
+	}
+	if width {
+		style.Width = Px(values.width)
+	}
+	if height {
+		style.Height = Px(values.height)
+	}
+	return style
+}
+
+// updateTransition starts a transition when the resolved style changed and advances the running one to now.
+// The previous and the new style are resolved against the same box, so that a resized parent does not start one.
+func (v View) updateTransition(now int64) {
+	var t = &v.transition
+	var style = v.resolveStyle()
+	var target = v.getTransitionValues(style)
+	if !t.initialized || !v.boxAssigned {
+		*t = transitionState{initialized: true, style: style, from: target, to: target, current: target}
+		return
+	}
+	var previous = v.getTransitionValues(t.style)
+	t.style = style
+	if target != previous {
+		if style.Transition == nil || style.Transition.Duration+style.Transition.Delay <= 0 {
+			*t = transitionState{initialized: true, style: style, from: target, to: target, current: target}
+			return
+		}
+		t.transition = *style.Transition
+		t.from = previous
+		if t.active {
+			t.from = t.current
+		}
+		t.to = target
+		t.width = animatesLength(t.from.width, t.to.width, t.from.widthUnit, t.to.widthUnit)
+		t.height = animatesLength(t.from.height, t.to.height, t.from.heightUnit, t.to.heightUnit)
+		t.start = now
+		t.active = true
+	} else if !t.active {
+		t.from, t.to, t.current = target, target, target
+	} else {
+		t.to = target
+	}
+	if !t.active {
+		return
+	}
+	var elapsed = now - t.start - t.transition.Delay
+	var rate = ease(t.transition.Easing, elapsed, t.transition.Duration)
+	t.current = lerpTransitionValues(t.from, t.to, rate)
+	t.overrides = transitionOverrides(t.current, t.width, t.height)
+	if elapsed >= t.transition.Duration {
+		t.active = false
+		t.overrides = ViewStyle{}
+	}
+}
+
+// updateTransitions advances the transitions of every view under components.
+func updateTransitions(components []Component, now int64) {
+	walkComponents(components, 0, func(component Component, depth int) {
+		if view, ok := component.(View); ok {
+			view.updateTransition(now)
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package game_ui
+
+import (
+	"image/color"
+	"math"
+	"unicode/utf8"
+)
+
+// GlyphEffect animates every glyph of a span on its laid-out position.
+type GlyphEffect = string
+
+const (
+	EffectWave    GlyphEffect = "wave"
+	EffectShake   GlyphEffect = "shake"
+	EffectRainbow GlyphEffect = "rainbow"
+)
+
+// DefaultRevealSpeed is the speed of a TextReveal that does not set one, in characters per second.
+const DefaultRevealSpeed = 30.0
+
+// DefaultRevealPauses are the msec waited after punctuation by a TextReveal that does not set its Pauses.
+var DefaultRevealPauses = map[rune]int64{
+	'.': 300, '!': 300, '?': 300, ',': 150, ';': 150, ':': 150,
+	'。': 300, '！': 300, '？': 300, '、': 150, '…': 400,
+}
+
+// TextReveal shows the characters of a Text one by one, like a typewriter.
+// The Speed of a span multiplies the speed, and [pause] and [marker] spans of ParseMarkup are waited and reported on the way.
+type TextReveal struct {
+	Speed float64 // characters per second
+	Delay int64   // msec
+	// msec waited after a character
+	Pauses   map[rune]int64
+	OnMarker func(name string)
+	// called once every character is shown, SkipReveal included
+	OnComplete func()
+}
+
+var noReveal = textReveal{visible: -1}
+
+// revealStep is a character shown, a pause or a marker reached, after wait msec.
+type revealStep struct {
+	wait   int64
+	char   bool
+	marker string
+}
+
+type textReveal struct {
+	reveal  TextReveal
+	steps   []revealStep
+	step    int
+	playing bool
+	started bool
+	// time the last step was taken
+	last int64
+	// characters shown, -1 when the text is not revealed
+	visible int
+}
+
+// revealSteps returns the steps that reveal the characters of layout, laid out from spans.
+func revealSteps(reveal TextReveal, spans []TextSpan, layout textLayout) []revealStep {
+	var speed = reveal.Speed
+	if speed <= 0 {
+		speed = DefaultRevealSpeed
+	}
+	var pauses = reveal.Pauses
+	if pauses == nil {
+		pauses = DefaultRevealPauses
+	}
+	var steps = []revealStep{{wait: reveal.Delay}}
+	var char = 0
+	for i, span := range spans {
+		if span.Marker != "" {
+			steps = append(steps, revealStep{marker: span.Marker})
+		}
+		if span.Pause > 0 {
+			steps = append(steps, revealStep{wait: span.Pause})
+		}
+		for ; char < len(layout.chars) && layout.chars[char].run == i; char++ {
+			var run = layout.runs[i]
+			var interval = 1000 / (speed * run.speed)
+			steps = append(steps, revealStep{wait: int64(math.Round(interval)), char: true})
+			var last, _ = utf8.DecodeLastRuneInString(layout.chars[char].text)
+			if pause := pauses[last]; pause > 0 {
+				steps = append(steps, revealStep{wait: pause})
+			}
+		}
+	}
+	return steps
+}
+
+// take takes the step and reports a marker.
+func (r *textReveal) take(step revealStep) {
+	r.step++
+	if step.char {
+		r.visible++
+	}
+	if step.marker != "" && r.reveal.OnMarker != nil {
+		r.reveal.OnMarker(step.marker)
+	}
+}
+
+func (r *textReveal) complete() {
+	r.playing = false
+	r.visible = -1
+	if r.reveal.OnComplete != nil {
+		r.reveal.OnComplete()
+	}
+}
+
+// StartReveal hides the characters and shows them one by one from the next Window.Update.
+// Changing the spans stops the reveal and shows the whole text.
+func (t Text) StartReveal(reveal TextReveal) {
+	t.reveal = textReveal{reveal: reveal, playing: true}
+	t.effects.invalidate()
+}
+
+// SkipReveal shows the rest of the characters at once, the markers left are reported on the way.
+func (t Text) SkipReveal() {
+	if !t.reveal.playing {
+		return
+	}
+	t.prepareReveal()
+	for t.reveal.step < len(t.reveal.steps) {
+		t.reveal.take(t.reveal.steps[t.reveal.step])
+	}
+	t.reveal.complete()
+	t.effects.invalidate()
+}
+
+func (t Text) IsRevealing() bool {
+	return t.reveal.playing
+}
+
+// SetVisibleChars stops the reveal and shows the first n characters, n < 0 shows all of them.
+func (t Text) SetVisibleChars(n int) {
+	t.reveal = textReveal{visible: max(n, -1)}
+	t.effects.invalidate()
+}
+
+// VisibleChars returns the number of characters shown.
+func (t Text) VisibleChars() int {
+	if t.reveal.visible < 0 {
+		return t.CharCount()
+	}
+	return min(t.reveal.visible, t.CharCount())
+}
+
+// CharCount returns the number of characters of the text, an image counts as one.
+func (t Text) CharCount() int {
+	t.GetSize()
+	return len(t.layout.chars)
+}
+
+func (t Text) prepareReveal() {
+	if t.reveal.steps == nil {
+		t.GetSize()
+		t.reveal.steps = revealSteps(t.reveal.reveal, t.spans, t.layout)
+	}
+}
+
+// update advances the reveal and the glyph effects to now.
+func (t Text) update(now int64) {
+	t.now = now
+	if t.layout.animated {
+		t.effects.invalidate()
+	}
+	if !t.reveal.playing {
+		return
+	}
+	t.prepareReveal()
+	var r = &t.reveal
+	if !r.started {
+		r.last = now
+		r.started = true
+	}
+	var visible = r.visible
+	for r.step < len(r.steps) && now-r.last >= r.steps[r.step].wait {
+		r.last += r.steps[r.step].wait
+		r.take(r.steps[r.step])
+	}
+	if r.visible != visible {
+		t.effects.invalidate()
+	}
+	if r.step == len(r.steps) {
+		r.complete()
+	}
+}
+
+// updateTexts advances the reveal and the glyph effects of the texts under components.
+func updateTexts(components []Component, now int64) {
+	walkComponents(components, 0, func(component Component, depth int) {
+		if text, ok := component.(Text); ok {
+			text.update(now)
+		}
+	})
+}
+
+// glyphStyle is how a glyph is drawn at the moment, the glyph is drawn as laid out when it is zero.
+type glyphStyle struct {
+	hidden bool
+	dx, dy float64
+	// the color of the run when nil
+	color color.Color
+}
+
+type glyphStyler func(glyph textGlyph, run textRun) glyphStyle
+
+// glyphStyler returns the style of the glyphs by the reveal and the effects, nil when every glyph is drawn as laid out.
+func (t Text) glyphStyler() glyphStyler {
+	var visible = t.reveal.visible
+	if visible < 0 && !t.layout.animated {
+		return nil
+	}
+	var now = float64(t.now) / 1000
+	return func(glyph textGlyph, run textRun) glyphStyle {
+		if visible >= 0 && glyph.index >= visible {
+			return glyphStyle{hidden: true}
+		}
+		var style = glyphStyle{}
+		var index = float64(glyph.index)
+		switch run.effect {
+		case EffectWave:
+			style.dy = math.Sin((now*1.5-index*0.1)*2*math.Pi) * run.size * 0.15
+		case EffectShake:
+			var frame = math.Floor(now * 20)
+			var amount = max(run.size*0.08, 1)
+			style.dx = (noise(index, frame)*2 - 1) * amount
+			style.dy = (noise(index+0.5, frame)*2 - 1) * amount
+		case EffectRainbow:
+			var _, _, _, a = run.color.RGBA()
+			style.color = hueColor(now*0.5+index*0.08, uint8(a>>8))
+		}
+		return style
+	}
+}
+
+// noise returns a number from 0 to 1 that changes at random with x and y.
+func noise(x, y float64) float64 {
+	var v = math.Sin(x*12.9898+y*78.233) * 43758.5453
+	return v - math.Floor(v)
+}
+
+// hueColor returns the saturated color of hue, 0 to 1 from red around to red, with alpha a.
+func hueColor(hue float64, a uint8) color.Color {
+	hue = (hue - math.Floor(hue)) * 6
+	var channel = func(n float64) uint8 {
+		var k = math.Mod(n+hue, 6)
+		var v = 1 - max(min(k, 4-k, 1), 0)
+		return uint8(math.Round(v * float64(a)))
+	}
+	return color.RGBA{R: channel(5), G: channel(3), B: channel(1), A: a}
+}
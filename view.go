@@ -5,16 +5,35 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
 type viewComponent struct {
-	components  []Component
-	size        *image.Point
-	style       ViewStyle
-	extraStyles []ViewStyle
-	drawnArea   image.Rectangle
-	screenSize  image.Point
+	components    []Component
+	size          *image.Point
+	style         ViewStyle
+	extraStyles   []ViewStyle
+	drawnArea     image.Rectangle
+	box           layoutBox
+	boxAssigned   bool
+	cache         viewRenderCache
+	fixedSize     *image.Point
+	scroll        scrollState
+	drawnRadius   [4]int
+	handlers      pointerHandlers
+	hovered       bool
+	pressed       bool
+	focusable     bool
+	focused       bool
+	focusStyle    *ViewStyle
+	focusHandlers focusHandlers
+	// keys handled by the view instead of the focus manager while it is focused
+	capturedKeys []ebiten.Key
+	disabled     bool
+	selected     bool
+	transition   transitionState
+	transform    transformState
+	shadows      []shadowRenderCache
+	background   backgroundImageCache
 }
 type View = *viewComponent
 type ViewStyle struct {
@@ -25,10 +44,36 @@ type ViewStyle struct {
 	/* top_left top_right bottom_right bottom_left */
 	Radius             *[4]int
 	Width, Height      *sizeSeg
+	MinWidth, MaxWidth *sizeSeg
+	MinHeight          *sizeSeg
+	MaxHeight          *sizeSeg
 	Direction          *DirectionType
 	PositionHorizontal *PositionType
 	PositionVertical   *PositionType
 	IsFloating         bool
+	Gap                *sizeSeg
+	FlexGrow           *float32
+	FlexShrink         *float32
+	Overflow           *OverflowType
+	Scrollbar          *ScrollbarStyle
+	// offsets of a floating view from the padding box of the parent or the screen
+	Top, Right   *sizeSeg
+	Bottom, Left *sizeSeg
+	AnchorTo     *AnchorType
+	ZIndex       *int
+	// merged over the style while the view is in the state, in this order
+	Selected, Focused *ViewStyle
+	Hover, Pressed    *ViewStyle
+	Disabled          *ViewStyle
+	Transition        *Transition
+	BoxShadow         *[]BoxShadow
+	BackgroundImage   *BackgroundImage
+	// applied to the view and its descendants without changing the layout
+	Opacity  *float64
+	Scale    *float64
+	Rotation *float64 // radian
+	// point the view scales and rotates around, relative to its size, center by default
+	TransformOrigin *[2]float64
 }
 
 type DirectionType = string
@@ -44,6 +89,11 @@ const (
 	First  PositionType = "first"
 	Center PositionType = "center"
 	Last   PositionType = "last"
+
+	// distribute the free space between children along the direction
+	SpaceBetween PositionType = "space-between"
+	SpaceAround  PositionType = "space-around"
+	SpaceEvenly  PositionType = "space-evenly"
 )
 
 func mergeViewStyle(target ViewStyle, styles []ViewStyle) ViewStyle {
@@ -72,6 +122,18 @@ func mergeViewStyle(target ViewStyle, styles []ViewStyle) ViewStyle {
 		if styles[i].Height != nil {
 			target.Height = styles[i].Height
 		}
+		if styles[i].MinWidth != nil {
+			target.MinWidth = styles[i].MinWidth
+		}
+		if styles[i].MaxWidth != nil {
+			target.MaxWidth = styles[i].MaxWidth
+		}
+		if styles[i].MinHeight != nil {
+			target.MinHeight = styles[i].MinHeight
+		}
+		if styles[i].MaxHeight != nil {
+			target.MaxHeight = styles[i].MaxHeight
+		}
 		if styles[i].Direction != nil {
 			target.Direction = styles[i].Direction
 		}
@@ -82,6 +144,75 @@ func mergeViewStyle(target ViewStyle, styles []ViewStyle) ViewStyle {
 			target.PositionVertical = styles[i].PositionVertical
 		}
 		target.IsFloating = target.IsFloating || styles[i].IsFloating
+		if styles[i].Gap != nil {
+			target.Gap = styles[i].Gap
+		}
+		if styles[i].FlexGrow != nil {
+			target.FlexGrow = styles[i].FlexGrow
+		}
+		if styles[i].FlexShrink != nil {
+			target.FlexShrink = styles[i].FlexShrink
+		}
+		if styles[i].Overflow != nil {
+			target.Overflow = styles[i].Overflow
+		}
+		if styles[i].Scrollbar != nil {
+			target.Scrollbar = styles[i].Scrollbar
+		}
+		if styles[i].Top != nil {
+			target.Top = styles[i].Top
+		}
+		if styles[i].Right != nil {
+			target.Right = styles[i].Right
+		}
+		if styles[i].Bottom != nil {
+			target.Bottom = styles[i].Bottom
+		}
+		if styles[i].Left != nil {
+			target.Left = styles[i].Left
+		}
+		if styles[i].AnchorTo != nil {
+			target.AnchorTo = styles[i].AnchorTo
+		}
+		if styles[i].ZIndex != nil {
+			target.ZIndex = styles[i].ZIndex
+		}
+		if styles[i].Selected != nil {
+			target.Selected = styles[i].Selected
+		}
+		if styles[i].Focused != nil {
+			target.Focused = styles[i].Focused
+		}
+		if styles[i].Hover != nil {
+			target.Hover = styles[i].Hover
+		}
+		if styles[i].Pressed != nil {
+			target.Pressed = styles[i].Pressed
+		}
+		if styles[i].Disabled != nil {
+			target.Disabled = styles[i].Disabled
+		}
+		if styles[i].Transition != nil {
+			target.Transition = styles[i].Transition
+		}
+		if styles[i].BoxShadow != nil {
+			target.BoxShadow = styles[i].BoxShadow
+		}
+		if styles[i].BackgroundImage != nil {
+			target.BackgroundImage = styles[i].BackgroundImage
+		}
+		if styles[i].Opacity != nil {
+			target.Opacity = styles[i].Opacity
+		}
+		if styles[i].Scale != nil {
+			target.Scale = styles[i].Scale
+		}
+		if styles[i].Rotation != nil {
+			target.Rotation = styles[i].Rotation
+		}
+		if styles[i].TransformOrigin != nil {
+			target.TransformOrigin = styles[i].TransformOrigin
+		}
 	}
 	return target
 }
@@ -117,19 +248,177 @@ func (v View) GetStylesCount() int {
 	return len(v.extraStyles)
 }
 
-func getSizePx(screenSize image.Point, size [4]sizeSeg) (int, int, int, int) {
-	return calcSize(screenSize, size[0]), calcSize(screenSize, size[1]), calcSize(screenSize, size[2]), calcSize(screenSize, size[3])
+// getStyle returns the resolved style with the values of a running transition.
+func (v View) getStyle() ViewStyle {
+	var style = v.resolveStyle()
+	if v.transition.active {
+		style = mergeViewStyle(style, []ViewStyle{v.transition.overrides})
+	}
+	return style
+}
+
+// resolveStyle returns the style of the view with the extra styles and then the styles of its current states merged.
+func (v View) resolveStyle() ViewStyle {
+	var style = mergeViewStyle(v.style, v.extraStyles)
+	var states = []ViewStyle{}
+	for _, state := range []struct {
+		active bool
+		style  *ViewStyle
+	}{
+		{v.selected, style.Selected},
+		{v.focused, v.focusStyle},
+		{v.focused, style.Focused},
+		{v.hovered, style.Hover},
+		{v.pressed, style.Pressed},
+		{v.disabled, style.Disabled},
+	} {
+		if state.active && state.style != nil {
+			states = append(states, *state.style)
+		}
+	}
+	return mergeViewStyle(style, states)
+}
+
+func (v View) SetDisabled(disabled bool) {
+	v.disabled = disabled
+}
+func (v View) IsDisabled() bool {
+	return v.disabled
+}
+func (v View) SetSelected(selected bool) {
+	v.selected = selected
+}
+func (v View) IsSelected() bool {
+	return v.selected
+}
+
+func getSizePx(box layoutBox, size [4]sizeSeg) (int, int, int, int) {
+	return calcSize(box, size[0]), calcSize(box, size[1]), calcSize(box, size[2]), calcSize(box, size[3])
+}
+
+func (v View) getInsets(style ViewStyle) image.Point {
+	var x, y = 0, 0
+	for _, size := range []*[4]sizeSeg{style.Padding, style.Margin, style.BorderWidth} {
+		if size == nil {
+			continue
+		}
+		var top, right, bottom, left = getSizePx(v.box, *size)
+		x += left + right
+		y += top + bottom
+	}
+	return image.Point{X: x, Y: y}
+}
+
+func (v View) setLayoutBox(box layoutBox) {
+	v.box = box
+	v.boxAssigned = true
+}
+
+// getChildBox returns the content box offered to the children.
+func (v View) getChildBox(style ViewStyle) layoutBox {
+	var available = v.box.parent
+	if v.fixedSize != nil {
+		available = *v.fixedSize
+	} else {
+		if width, ok := calcLength(v.box, style.Width, true); ok {
+			available.X = width
+		}
+		if height, ok := calcLength(v.box, style.Height, false); ok {
+			available.Y = height
+		}
+	}
+	var limit = v.box.limit
+	if maxWidth, ok := calcLength(v.box, style.MaxWidth, true); ok {
+		limit.X = limitLength(limit.X, max(maxWidth, 1))
+		available.X = min(available.X, maxWidth)
+	}
+	if maxHeight, ok := calcLength(v.box, style.MaxHeight, false); ok {
+		limit.Y = limitLength(limit.Y, max(maxHeight, 1))
+		available.Y = min(available.Y, maxHeight)
+	}
+	var insets = v.getInsets(style)
+	if limit.X > 0 {
+		limit.X = max(limit.X-insets.X, 1)
+	}
+	if limit.Y > 0 {
+		limit.Y = max(limit.Y-insets.Y, 1)
+	}
+	return layoutBox{
+		screen: v.box.screen,
+		parent: image.Point{X: max(available.X-insets.X, 0), Y: max(available.Y-insets.Y, 0)},
+		limit:  limit,
+	}
+}
+
+// layoutChildren passes the content box to the children.
+// Children filling the direction share the space left by their siblings.
+func (v View) layoutChildren(style ViewStyle) {
+	var box = v.getChildBox(style)
+	var vertical = isVertical(style)
+	var rest = mainAxis(box.parent, vertical)
+	var fills = []boxedComponent{}
+	var count = 0
+	for _, component := range v.components {
+		var boxed, ok = component.(boxedComponent)
+		if ok {
+			if _, anchor, _ := getPositionStyle(component); anchor == AnchorScreen && component.IsFloating() {
+				boxed.setLayoutBox(layoutBox{screen: box.screen, parent: box.screen})
+			} else {
+				boxed.setLayoutBox(box)
+			}
+		}
+		if component.IsFloating() {
+			continue
+		}
+		count++
+		if item, isFillItem := component.(fillItem); ok && isFillItem && item.fillsAxis(!vertical) {
+			fills = append(fills, boxed)
+		} else {
+			rest -= mainAxis(measureComponent(component), vertical)
+		}
+	}
+	if len(fills) == 0 {
+		return
+	}
+	if count > 1 {
+		rest -= v.getGap(style) * (count - 1)
+	}
+	for i, boxed := range fills {
+		var share = max(rest, 0) / len(fills)
+		if i < max(rest, 0)%len(fills) {
+			share++
+		}
+		boxed.setLayoutBox(layoutBox{screen: box.screen, parent: withMainAxis(box.parent, vertical, share), limit: box.limit})
+	}
+}
+
+func (v View) fillsAxis(horizontal bool) bool {
+	var style = v.getStyle()
+	if horizontal {
+		return isFill(style.Width)
+	}
+	return isFill(style.Height)
+}
+
+func (v View) getGap(style ViewStyle) int {
+	if style.Gap == nil {
+		return 0
+	}
+	return calcSize(v.box, *style.Gap)
 }
 
 func (v View) getContentSize() image.Point {
 	var x, y = 0, 0
-	var style = mergeViewStyle(v.style, v.extraStyles)
+	var style = v.getStyle()
+	v.layoutChildren(style)
+	var count = 0
 	for _, component := range v.components {
 		if component.IsFloating() {
 			continue
 		}
-		var contentSize = component.GetSize()
-		if style.Direction == nil || *style.Direction == Vertical {
+		count++
+		var contentSize = measureComponent(component)
+		if isVertical(style) {
 			if x <= contentSize.X {
 				x = contentSize.X
 			}
@@ -141,29 +430,46 @@ func (v View) getContentSize() image.Point {
 			x += contentSize.X
 		}
 	}
-	for _, size := range []*[4]sizeSeg{style.Padding, style.Margin, style.BorderWidth} {
-		if size == nil {
-			continue
+	if count > 1 {
+		if isVertical(style) {
+			y += v.getGap(style) * (count - 1)
+		} else {
+			x += v.getGap(style) * (count - 1)
 		}
-		var top, right, bottom, left = getSizePx(v.screenSize, *size)
-		x += left + right
-		y += top + bottom
 	}
-	return image.Point{X: x, Y: y}
+	var insets = v.getInsets(style)
+	return image.Point{X: x + insets.X, Y: y + insets.Y}
 }
 
 func (v View) GetSize() image.Point {
+	if v.fixedSize != nil {
+		return *v.fixedSize
+	}
+	return v.measure()
+}
+
+func (v View) measure() image.Point {
 	var point = v.getContentSize()
 	var x = point.X
 	var y = point.Y
-	var style = mergeViewStyle(v.style, v.extraStyles)
-	var width = 0
-	if style.Width != nil {
-		width = calcSize(v.screenSize, *style.Width)
+	var style = v.getStyle()
+	var width, hasWidth = calcLength(v.box, style.Width, true)
+	var height, hasHeight = calcLength(v.box, style.Height, false)
+	// shrinkable children let the box keep its size instead of growing with the content
+	var shrinkable = v.getShrinkableSize(style)
+	if isVertical(style) && hasHeight && y > height {
+		y = max(height, y-shrinkable)
+	} else if !isVertical(style) && hasWidth && x > width {
+		x = max(width, x-shrinkable)
 	}
-	var height = 0
-	if style.Height != nil {
-		height = calcSize(v.screenSize, *style.Height)
+	// clipped content does not grow the box
+	if isClipping(style) {
+		if hasWidth {
+			x = width
+		}
+		if hasHeight {
+			y = height
+		}
 	}
 	if x < width {
 		x = width
@@ -171,25 +477,81 @@ func (v View) GetSize() image.Point {
 	if y < height {
 		y = height
 	}
-	return image.Point{X: x, Y: y}
+	return v.constrain(style, image.Point{X: x, Y: y})
+}
+
+// constrain applies MaxWidth/MaxHeight and then MinWidth/MinHeight.
+func (v View) constrain(style ViewStyle, size image.Point) image.Point {
+	if maxWidth, ok := calcLength(v.box, style.MaxWidth, true); ok && size.X > maxWidth {
+		size.X = maxWidth
+	}
+	if maxHeight, ok := calcLength(v.box, style.MaxHeight, false); ok && size.Y > maxHeight {
+		size.Y = maxHeight
+	}
+	if minWidth, ok := calcLength(v.box, style.MinWidth, true); ok && size.X < minWidth {
+		size.X = minWidth
+	}
+	if minHeight, ok := calcLength(v.box, style.MinHeight, false); ok && size.Y < minHeight {
+		size.Y = minHeight
+	}
+	return size
+}
+
+func (v View) getShrinkableSize(style ViewStyle) int {
+	var size = 0
+	for _, component := range v.components {
+		if item, ok := component.(flexItem); ok && !component.IsFloating() {
+			if _, shrink := item.flexFactors(); shrink > 0 {
+				size += mainAxis(item.measure(), isVertical(style))
+			}
+		}
+	}
+	return size
+}
+
+func (v View) flexFactors() (float32, float32) {
+	var style = v.getStyle()
+	var grow, shrink float32 = 0, 0
+	if style.FlexGrow != nil {
+		grow = *style.FlexGrow
+	}
+	if style.FlexShrink != nil {
+		shrink = *style.FlexShrink
+	}
+	return grow, shrink
+}
+
+func (v View) setFixedSize(size *image.Point) {
+	v.fixedSize = size
 }
 
 func (v View) Draw(screen *ebiten.Image, x, y int) {
-	var style = mergeViewStyle(v.style, v.extraStyles)
+	var style = v.getStyle()
+	var t = getStyleTransform(style.Opacity, style.Scale, style.Rotation, style.TransformOrigin)
+	v.transform.draw(screen, t, func(target *ebiten.Image, dx, dy int) image.Rectangle {
+		v.draw(target, x+dx, y+dy, style)
+		return v.drawnArea
+	})
+}
+
+func (v View) draw(screen *ebiten.Image, x, y int, style ViewStyle) {
 	var marginTop, marginRight, marginBottom, marginLeft int
 	var borderTop, borderRight, borderBottom, borderLeft int
 	var paddingTop, paddingRight, paddingBottom, paddingLeft int
 
-	v.screenSize = screen.Bounds().Size()
+	// a laid out component keeps the screen of its parent, it may be drawn on a clip layer
+	if !v.boxAssigned {
+		v.box = screenBox(screen)
+	}
 
 	if style.Margin != nil {
-		marginTop, marginRight, marginBottom, marginLeft = getSizePx(v.screenSize, *style.Margin)
+		marginTop, marginRight, marginBottom, marginLeft = getSizePx(v.box, *style.Margin)
 	}
 	if style.BorderWidth != nil {
-		borderTop, borderRight, borderBottom, borderLeft = getSizePx(v.screenSize, *style.BorderWidth)
+		borderTop, borderRight, borderBottom, borderLeft = getSizePx(v.box, *style.BorderWidth)
 	}
 	if style.Padding != nil {
-		paddingTop, paddingRight, paddingBottom, paddingLeft = getSizePx(v.screenSize, *style.Padding)
+		paddingTop, paddingRight, paddingBottom, paddingLeft = getSizePx(v.box, *style.Padding)
 	}
 	var radiusTopLeft, radiusTopRight, radiusBottomRight, radiusBottomLeft int
 	if style.Radius != nil {
@@ -206,9 +568,6 @@ func (v View) Draw(screen *ebiten.Image, x, y int) {
 	var contentSize = v.getContentSize()
 	var size = v.GetSize()
 
-	var positionH float64 = 0
-	var positionV float64 = 0
-
 	var radiusMin = size.X - marginWidth - borderWidth
 	if (size.Y - marginHeight - borderHeight) < radiusMin {
 		radiusMin = size.Y - marginHeight - borderHeight
@@ -227,166 +586,148 @@ func (v View) Draw(screen *ebiten.Image, x, y int) {
 		radiusBottomLeft = radiusMin
 	}
 
-	if style.PositionHorizontal != nil {
-		if *style.PositionHorizontal == Center {
-			positionH = 0.5
-		} else if *style.PositionHorizontal == Last {
-			positionH = 1
+	var minX, minY = x + marginLeft, y + marginTop
+	v.drawnArea = image.Rect(minX, minY, minX+size.X-marginWidth, minY+size.Y-marginHeight)
+	v.drawnRadius = [4]int{radiusTopLeft, radiusTopRight, radiusBottomRight, radiusBottomLeft}
+
+	var shadows []BoxShadow
+	if style.BoxShadow != nil {
+		shadows = *style.BoxShadow
+	}
+	if len(v.shadows) != len(shadows) {
+		for i := range v.shadows {
+			v.shadows[i].dispose()
 		}
+		v.shadows = make([]shadowRenderCache, len(shadows))
+	}
+	var drawShadowsOf = func(inset bool) {
+		drawShadows(screen, v.shadows, shadows, inset, x+marginLeft, y+marginTop, size.X-marginWidth, size.Y-marginHeight,
+			[4]int{borderTop, borderRight, borderBottom, borderLeft}, v.drawnRadius)
 	}
-	if style.PositionVertical != nil {
-		if *style.PositionVertical == Center {
-			positionV = 0.5
-		} else if *style.PositionVertical == Last {
-			positionV = 1
+	drawShadowsOf(false)
+
+	// draw border and base
+	var boxImage = v.cache.render(viewRenderKey{
+		width:           size.X - marginWidth,
+		height:          size.Y - marginHeight,
+		border:          [4]int{borderTop, borderRight, borderBottom, borderLeft},
+		radius:          [4]int{radiusTopLeft, radiusTopRight, radiusBottomRight, radiusBottomLeft},
+		borderColor:     colorKey(style.BorderColor),
+		backgroundColor: colorKey(style.BackgroundColor),
+	})
+	if boxImage != nil {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(x+marginLeft), float64(y+marginTop))
+		screen.DrawImage(boxImage, op)
+	}
+	if style.BackgroundImage != nil {
+		var backgroundImage = v.background.render(newBackgroundImageKey(*style.BackgroundImage, size.X-marginWidth, size.Y-marginHeight, v.drawnRadius))
+		if backgroundImage != nil {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(x+marginLeft), float64(y+marginTop))
+			screen.DrawImage(backgroundImage, op)
 		}
 	}
+	drawShadowsOf(true)
 
-	var contentLeft = int(positionH * float64(size.X-contentSize.X))
-	var contentTop = int(positionV * float64(size.Y-contentSize.Y))
-
-	var minX, minY = x + marginLeft, y + marginTop
-	v.drawnArea = image.Rect(minX, minY, minX+size.X-marginWidth, minY+size.Y-marginHeight)
-
-	// draw border
-	if style.BorderColor != nil {
-		var r1, g1, b1, a1 = style.BorderColor[0].RGBA()
-		var r2, g2, b2, a2 = style.BorderColor[1].RGBA()
-		var r3, g3, b3, a3 = style.BorderColor[2].RGBA()
-		var r4, g4, b4, a4 = style.BorderColor[3].RGBA()
-		if (borderWidth > 0 || borderHeight > 0) && (a1 > 0 || a2 > 0 || a3 > 0 || a4 > 0) {
-			var path = vector.Path{}
-			path.MoveTo(float32(radiusTopLeft), 0)
-			path.LineTo(float32(size.X-marginWidth-radiusTopRight), 0)
-			path.QuadTo(float32(size.X-marginWidth), 0, float32(size.X-marginWidth), float32(radiusTopRight))
-			path.LineTo(float32(size.X-marginWidth), float32(size.Y-marginHeight-radiusBottomRight))
-			path.QuadTo(float32(size.X-marginWidth), float32(size.Y-marginHeight), float32(size.X-marginWidth-radiusBottomRight), float32(size.Y-marginHeight))
-			path.LineTo(float32(radiusBottomLeft), float32(size.Y-marginHeight))
-			path.QuadTo(0, float32(size.Y-marginHeight), float32(0), float32(size.Y-marginHeight-radiusBottomLeft))
-			path.LineTo(float32(0), float32(radiusTopLeft))
-			path.QuadTo(0, 0, float32(radiusTopLeft), 0)
-
-			path.MoveTo(float32(borderLeft+radiusTopLeft), float32(borderTop))
-			path.LineTo(float32(size.X-marginWidth-borderRight-radiusTopRight), float32(borderTop))
-			path.QuadTo(float32(size.X-marginWidth-borderRight), float32(borderTop), float32(size.X-marginWidth-borderRight), float32(borderTop+radiusTopRight))
-			path.LineTo(float32(size.X-marginWidth-borderRight), float32(size.Y-marginHeight-borderBottom-radiusBottomRight))
-			path.QuadTo(float32(size.X-marginWidth-borderRight), float32(size.Y-marginHeight-borderBottom), float32(size.X-marginWidth-borderRight-radiusBottomRight), float32(size.Y-marginHeight-borderBottom))
-			path.LineTo(float32(borderLeft+radiusBottomLeft), float32(size.Y-marginHeight-borderBottom))
-			path.QuadTo(float32(borderLeft), float32(size.Y-marginHeight-borderBottom), float32(borderLeft), float32(size.Y-marginHeight-borderBottom-radiusBottomLeft))
-			path.LineTo(float32(borderLeft), float32(borderTop+radiusTopLeft))
-			path.QuadTo(float32(borderLeft), float32(borderTop), float32(borderLeft+radiusTopLeft), float32(borderTop))
-			path.Close()
-
-			// Size of gradient rectangle
-			var rectW = float32(size.X - marginWidth)
-			var rectH = float32(size.Y - marginHeight)
-
-			// Vertices for gradient (rectangle)
-			gradientVertices := []ebiten.Vertex{
-				{DstX: 0, DstY: 0, SrcX: 1, SrcY: 1, ColorR: float32(r1) / 0xffff, ColorG: float32(g1) / 0xffff, ColorB: float32(b1) / 0xffff, ColorA: float32(a1) / 0xffff},
-				{DstX: rectW, DstY: 0, SrcX: 1, SrcY: 1, ColorR: float32(r2) / 0xffff, ColorG: float32(g2) / 0xffff, ColorB: float32(b2) / 0xffff, ColorA: float32(a2) / 0xffff},
-				{DstX: 0, DstY: rectH, SrcX: 1, SrcY: 1, ColorR: float32(r4) / 0xffff, ColorG: float32(g4) / 0xffff, ColorB: float32(b4) / 0xffff, ColorA: float32(a4) / 0xffff},
-				{DstX: rectW, DstY: rectH, SrcX: 1, SrcY: 1, ColorR: float32(r3) / 0xffff, ColorG: float32(g3) / 0xffff, ColorB: float32(b3) / 0xffff, ColorA: float32(a3) / 0xffff},
-			}
-			gradientIndices := []uint16{0, 1, 2, 1, 3, 2}
-
-			// Create off-screen buffer
-			maskBuffer := ebiten.NewImage(int(rectW), int(rectH))
-
-			// Draw mask (path shape)
-			drawOp := &vector.DrawPathOptions{}
-			drawOp.ColorScale.ScaleWithColor(color.White)
-			vector.FillPath(maskBuffer, &path, &vector.FillOptions{FillRule: vector.FillRuleEvenOdd}, drawOp)
-
-			// Composite gradient with BlendSourceIn (clip by mask alpha)
-			op := &ebiten.DrawTrianglesOptions{}
-			op.Blend = ebiten.BlendSourceIn
-			maskBuffer.DrawTriangles(gradientVertices, gradientIndices, emptySubImage, op)
-
-			// Draw to screen
-			screenOp := &ebiten.DrawImageOptions{}
-			screenOp.GeoM.Translate(float64(x+marginLeft), float64(y+marginTop))
-			screen.DrawImage(maskBuffer, screenOp)
-		}
-	}
-
-	// draw base
-	if style.BackgroundColor != nil {
-		var r1, g1, b1, a1 = style.BackgroundColor[0].RGBA()
-		var r2, g2, b2, a2 = style.BackgroundColor[1].RGBA()
-		var r3, g3, b3, a3 = style.BackgroundColor[2].RGBA()
-		var r4, g4, b4, a4 = style.BackgroundColor[3].RGBA()
-		if a1 > 0 || a2 > 0 || a3 > 0 || a4 > 0 {
-			var path = vector.Path{}
-			path.MoveTo(float32(borderLeft+radiusTopLeft), float32(borderTop))
-			path.LineTo(float32(size.X-marginWidth-borderRight-radiusTopRight), float32(borderTop))
-			path.QuadTo(float32(size.X-marginWidth-borderRight), float32(borderTop), float32(size.X-marginWidth-borderRight), float32(borderTop+radiusTopRight))
-			path.LineTo(float32(size.X-marginWidth-borderRight), float32(size.Y-marginHeight-borderBottom-radiusBottomRight))
-			path.QuadTo(float32(size.X-marginWidth-borderRight), float32(size.Y-marginHeight-borderBottom), float32(size.X-marginWidth-borderRight-radiusBottomRight), float32(size.Y-marginHeight-borderBottom))
-			path.LineTo(float32(borderLeft+radiusBottomLeft), float32(size.Y-marginHeight-borderBottom))
-			path.QuadTo(float32(borderLeft), float32(size.Y-marginHeight-borderBottom), float32(borderLeft), float32(size.Y-marginHeight-borderBottom-radiusBottomLeft))
-			path.LineTo(float32(borderLeft), float32(borderTop+radiusTopLeft))
-			path.QuadTo(float32(borderLeft), float32(borderTop), float32(borderLeft+radiusTopLeft), float32(borderTop))
-			path.Close()
-
-			// Size of background gradient rectangle
-			var bgRectW = float32(size.X - marginWidth)
-			var bgRectH = float32(size.Y - marginHeight)
-
-			// Vertices for gradient (rectangle)
-			bgGradientVertices := []ebiten.Vertex{
-				{DstX: 0, DstY: 0, SrcX: 1, SrcY: 1, ColorR: float32(r1) / 0xffff, ColorG: float32(g1) / 0xffff, ColorB: float32(b1) / 0xffff, ColorA: float32(a1) / 0xffff},
-				{DstX: bgRectW, DstY: 0, SrcX: 1, SrcY: 1, ColorR: float32(r2) / 0xffff, ColorG: float32(g2) / 0xffff, ColorB: float32(b2) / 0xffff, ColorA: float32(a2) / 0xffff},
-				{DstX: 0, DstY: bgRectH, SrcX: 1, SrcY: 1, ColorR: float32(r4) / 0xffff, ColorG: float32(g4) / 0xffff, ColorB: float32(b4) / 0xffff, ColorA: float32(a4) / 0xffff},
-				{DstX: bgRectW, DstY: bgRectH, SrcX: 1, SrcY: 1, ColorR: float32(r3) / 0xffff, ColorG: float32(g3) / 0xffff, ColorB: float32(b3) / 0xffff, ColorA: float32(a3) / 0xffff},
-			}
-			bgGradientIndices := []uint16{0, 1, 2, 1, 3, 2}
-
-			// Create off-screen buffer
-			bgMaskBuffer := ebiten.NewImage(int(bgRectW), int(bgRectH))
-
-			// Draw mask (path shape)
-			bgDrawOp := &vector.DrawPathOptions{}
-			bgDrawOp.ColorScale.ScaleWithColor(color.White)
-			vector.FillPath(bgMaskBuffer, &path, nil, bgDrawOp)
-
-			// Composite gradient with BlendSourceIn (clip by mask alpha)
-			bgOp := &ebiten.DrawTrianglesOptions{}
-			bgOp.Blend = ebiten.BlendSourceIn
-			bgMaskBuffer.DrawTriangles(bgGradientVertices, bgGradientIndices, emptySubImage, bgOp)
-
-			// Draw to screen
-			bgScreenOp := &ebiten.DrawImageOptions{}
-			bgScreenOp.GeoM.Translate(float64(x+marginLeft), float64(y+marginTop))
-			screen.DrawImage(bgMaskBuffer, bgScreenOp)
+	var vertical = isVertical(style)
+	var mainPosition, crossPosition = style.PositionVertical, style.PositionHorizontal
+	if !vertical {
+		mainPosition, crossPosition = style.PositionHorizontal, style.PositionVertical
+	}
+	var crossRate = positionRate(crossPosition)
+	var insets = image.Point{X: marginWidth + borderWidth + paddingWidth, Y: marginHeight + borderHeight + paddingHeight}
+
+	var sizes = make([]image.Point, len(v.components))
+	var count = 0
+	for i, component := range v.components {
+		sizes[i] = measureComponent(component)
+		if !component.IsFloating() {
+			count++
+		}
+	}
+	var free = flexChildren(v.components, sizes, vertical, mainAxis(size, vertical)-mainAxis(contentSize, vertical))
+	var scrolling = isScrolling(style)
+	if scrolling && free < 0 {
+		free = 0
+	}
+	var lead, between = distributeSpace(mainPosition, free, count)
+	var gap = v.getGap(style) + between
+
+	var paddingArea = image.Rect(minX+borderLeft, minY+borderTop, minX+size.X-marginWidth-borderRight, minY+size.Y-marginHeight-borderBottom)
+	var target = screen
+	var scrollX, scrollY = 0, 0
+	if isClipping(style) {
+		v.scroll.clipArea = paddingArea
+		target = v.scroll.clipLayer(screen)
+		if scrolling {
+			// the children and the padding are scrolled through the padding box, the viewport of the scrollbars
+			var scrolled = contentSize.Sub(image.Point{X: marginWidth + borderWidth, Y: marginHeight + borderHeight})
+			v.scroll.max = image.Point{X: max(scrolled.X-paddingArea.Dx(), 0), Y: max(scrolled.Y-paddingArea.Dy(), 0)}
+			v.ScrollTo(v.scroll.offset.X, v.scroll.offset.Y)
+			scrollX, scrollY = v.scroll.offset.X, v.scroll.offset.Y
 		}
 	}
+	if !scrolling {
+		v.scroll.max = image.Point{}
+	}
 
-	var _x = marginLeft + borderLeft + paddingLeft + contentLeft
-	var _y = marginTop + borderTop + paddingTop + contentTop
-	for _, component := range v.components {
-		var componentSize = component.GetSize()
-		var __x = int(positionH * float64((contentSize.X-marginWidth-borderWidth-paddingWidth)-componentSize.X))
-		var __y = int(positionV * float64((contentSize.Y-marginHeight-borderHeight-paddingHeight)-componentSize.Y))
-		if style.Direction == nil || *style.Direction == Vertical {
-			__y = 0
-		} else {
-			__x = 0
+	var contentCross = crossAxis(contentSize, vertical) - crossAxis(insets, vertical)
+	var cursor = lead
+	var crossStart = int(crossRate * float64(crossAxis(size, vertical)-crossAxis(contentSize, vertical)))
+	var contentX, contentY = x + marginLeft + borderLeft + paddingLeft - scrollX, y + marginTop + borderTop + paddingTop - scrollY
+	var childBox = v.getChildBox(style)
+	var positions = make([]image.Point, len(v.components))
+	for i, component := range v.components {
+		var componentSize = sizes[i]
+		var offset = crossStart + int(crossRate*float64(contentCross-crossAxis(componentSize, vertical)))
+		var _x, _y = cursor, offset
+		if vertical {
+			_x, _y = offset, cursor
 		}
-		component.Draw(screen, x+_x+__x, y+_y+__y)
+		positions[i] = image.Point{X: contentX + _x, Y: contentY + _y}
 		if component.IsFloating() {
+			if _, anchor, _ := getPositionStyle(component); anchor == AnchorScreen {
+				positions[i] = placeFloating(component, layoutBox{screen: v.box.screen, parent: v.box.screen}, image.Rectangle{Max: v.box.screen}, positions[i])
+			} else {
+				positions[i] = placeFloating(component, childBox, paddingArea.Sub(image.Point{X: scrollX, Y: scrollY}), positions[i])
+			}
 			continue
 		}
-		if style.Direction == nil || *style.Direction == Vertical {
-			_y += componentSize.Y
+		cursor += mainAxis(componentSize, vertical) + gap
+	}
+	for _, i := range drawingOrder(v.components) {
+		var component = v.components[i]
+		if _, anchor, _ := getPositionStyle(component); anchor == AnchorScreen && component.IsFloating() {
+			component.Draw(screen, positions[i].X, positions[i].Y)
 		} else {
-			_x += componentSize.X
+			component.Draw(target, positions[i].X, positions[i].Y)
 		}
 	}
+
+	if isClipping(style) {
+		v.scroll.drawClipped(screen, [4]int{radiusTopLeft, radiusTopRight, radiusBottomRight, radiusBottomLeft})
+		if scrolling {
+			v.scroll.drawScrollbars(screen, v.box, style.Scrollbar)
+		}
+	}
+}
+
+func (v View) positionStyle() ([4]*sizeSeg, AnchorType, int) {
+	var style = v.getStyle()
+	var anchor = AnchorParent
+	if style.AnchorTo != nil {
+		anchor = *style.AnchorTo
+	}
+	var zIndex = 0
+	if style.ZIndex != nil {
+		zIndex = *style.ZIndex
+	}
+	return [4]*sizeSeg{style.Top, style.Right, style.Bottom, style.Left}, anchor, zIndex
 }
 
 func (v View) IsFloating() bool {
-	var style = mergeViewStyle(v.style, v.extraStyles)
+	var style = v.getStyle()
 	return style.IsFloating
 }
 
@@ -395,5 +736,42 @@ func (v View) Components() []Component {
 }
 
 func (v View) Area() image.Rectangle {
-	return v.drawnArea
+	return v.transform.area(v.drawnArea)
+}
+
+func (v View) untransformPoint(point image.Point) image.Point {
+	return v.transform.untransform(point, v.drawnArea)
+}
+
+func (v View) containsPoint(point image.Point) bool {
+	return insideRoundedRect(point, v.drawnArea, v.drawnRadius)
+}
+
+func (v View) drawnComponents() []Component {
+	var components = make([]Component, len(v.components))
+	for i, index := range drawingOrder(v.components) {
+		components[i] = v.components[index]
+	}
+	return components
+}
+
+func (v View) clipArea() (image.Rectangle, bool) {
+	var style = v.getStyle()
+	return v.scroll.clipArea, isClipping(style)
+}
+
+// Dispose releases the cached images of the view and its descendants.
+func (v View) Dispose() {
+	v.cache.dispose()
+	v.scroll.dispose()
+	v.transform.dispose()
+	for i := range v.shadows {
+		v.shadows[i].dispose()
+	}
+	v.background.dispose()
+	for _, component := range v.components {
+		if d, ok := component.(disposer); ok {
+			d.Dispose()
+		}
+	}
 }
@@ -2,36 +2,176 @@ package game_ui
 
 import (
 	"image"
+	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 type windowComponent struct {
-	components []Component
+	layers     []*windowLayer
+	drawnArea  image.Rectangle
+	lastUpdate int64
+	pointer    pointerState
+	scrollDrag scrollDrag
+	dispatcher pointerDispatcher
+	focus      FocusManager
 }
 type Window = *windowComponent
 
+type LayerType = string
+
+const (
+	LayerBackground LayerType = "background"
+	LayerContent    LayerType = "content"
+	LayerPopup      LayerType = "popup"
+	LayerOverlay    LayerType = "overlay"
+	LayerDebug      LayerType = "debug"
+)
+
+var defaultLayerOrders = map[LayerType]int{
+	LayerBackground: 0,
+	LayerContent:    100,
+	LayerPopup:      200,
+	LayerOverlay:    300,
+	LayerDebug:      400,
+}
+
+type windowLayer struct {
+	name       LayerType
+	order      int
+	hidden     bool
+	components []Component
+}
+
 func NewWindow(components []Component) Window {
-	return &windowComponent{components}
+	var w = &windowComponent{}
+	w.focus = newFocusManager(w)
+	w.SetLayer(LayerContent, components)
+	return w
+}
+
+// getLayer returns the layer named name, creating it when it does not exist yet.
+func (w Window) getLayer(name LayerType) *windowLayer {
+	for _, layer := range w.layers {
+		if layer.name == name {
+			return layer
+		}
+	}
+	var layer = &windowLayer{name: name, order: defaultLayerOrders[name]}
+	w.layers = append(w.layers, layer)
+	w.sortLayers()
+	return layer
+}
+
+func (w Window) sortLayers() {
+	sort.SliceStable(w.layers, func(i, j int) bool {
+		return w.layers[i].order < w.layers[j].order
+	})
+}
+
+func (w Window) SetLayer(name LayerType, components []Component) {
+	w.getLayer(name).components = components
+}
+
+func (w Window) AddToLayer(name LayerType, components ...Component) {
+	var layer = w.getLayer(name)
+	layer.components = append(layer.components, components...)
+}
+
+func (w Window) RemoveFromLayer(name LayerType, component Component) {
+	var layer = w.getLayer(name)
+	for i := range layer.components {
+		if layer.components[i] == component {
+			layer.components = append(layer.components[:i:i], layer.components[i+1:]...)
+			return
+		}
+	}
+}
+
+func (w Window) LayerComponents(name LayerType) []Component {
+	return w.getLayer(name).components
+}
+
+// SetLayerOrder moves a layer, layers with a larger order are drawn above.
+// The default layers are ordered background(0), content(100), popup(200), overlay(300) and debug(400).
+func (w Window) SetLayerOrder(name LayerType, order int) {
+	w.getLayer(name).order = order
+	w.sortLayers()
+}
+
+func (w Window) SetLayerVisible(name LayerType, visible bool) {
+	w.getLayer(name).hidden = !visible
+}
+
+// visibleLayers returns the shown layers from bottom to top.
+func (w Window) visibleLayers() []*windowLayer {
+	var layers = []*windowLayer{}
+	for _, layer := range w.layers {
+		if !layer.hidden {
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// Update handles the input of the components, now is the current time in milliseconds.
+func (w Window) Update(now int64) {
+	var ctx = newUpdateContext(&w.pointer, now, w.lastUpdate)
+	w.lastUpdate = now
+	var components = w.drawnComponents()
+	var path = hitTestPath(components, ctx.pointer)
+	var dragged = w.scrollDrag.dragging
+	updateScroll(components, path, ctx, &w.scrollDrag)
+	dragged = dragged || w.scrollDrag.dragging
+	w.dispatcher.dispatch(path, ctx, dragged)
+	w.focus.update(ctx, path)
+	updateTextInputs(w.Components(), path, ctx, w.focus)
+	updateKeyboards(w.Components())
+	updateTransitions(w.Components(), ctx.now)
+	updateAnimations(w.Components(), ctx.now)
+	updateTexts(w.Components(), ctx.now)
 }
 
 func (w Window) GetSize() image.Point {
 	var x, y = 0, 0
-	for _, component := range w.components {
-		var contentSize = component.GetSize()
-		if x <= contentSize.X {
-			x = contentSize.X
+	for _, layer := range w.visibleLayers() {
+		var layerY = 0
+		for _, component := range layer.components {
+			if component.IsFloating() {
+				continue
+			}
+			var contentSize = component.GetSize()
+			if x <= contentSize.X {
+				x = contentSize.X
+			}
+			layerY += contentSize.Y
+		}
+		if y < layerY {
+			y = layerY
 		}
-		y += contentSize.Y
 	}
 	return image.Point{X: x, Y: y}
 }
 
 func (w Window) Draw(screen *ebiten.Image, x, y int) {
-	var _y = 0
-	for _, component := range w.components {
-		component.Draw(screen, x, y+_y)
-		_y += component.GetSize().Y
+	var box = screenBox(screen)
+	var size = w.GetSize()
+	w.drawnArea = image.Rect(x, y, x+size.X, y+size.Y)
+	for _, layer := range w.visibleLayers() {
+		var _y = 0
+		for _, i := range drawingOrder(layer.components) {
+			var component = layer.components[i]
+			if boxed, ok := component.(boxedComponent); ok {
+				boxed.setLayoutBox(box)
+			}
+			if component.IsFloating() {
+				var position = placeFloating(component, box, image.Rectangle{Max: box.screen}, image.Point{X: x, Y: y})
+				component.Draw(screen, position.X, position.Y)
+				continue
+			}
+			component.Draw(screen, x, y+_y)
+			_y += component.GetSize().Y
+		}
 	}
 }
 
@@ -39,6 +179,36 @@ func (w Window) IsFloating() bool {
 	return false
 }
 
+func (w Window) drawnComponents() []Component {
+	var components = []Component{}
+	for _, layer := range w.visibleLayers() {
+		for _, i := range drawingOrder(layer.components) {
+			components = append(components, layer.components[i])
+		}
+	}
+	return components
+}
+
+// Components returns the components of the visible layers from bottom to top.
 func (w Window) Components() []Component {
-	return w.components
+	var components = []Component{}
+	for _, layer := range w.visibleLayers() {
+		components = append(components, layer.components...)
+	}
+	return components
+}
+
+func (w Window) Area() image.Rectangle {
+	return w.drawnArea
+}
+
+// Dispose releases the cached images held by the components of the window.
+func (w Window) Dispose() {
+	for _, layer := range w.layers {
+		for _, component := range layer.components {
+			if d, ok := component.(disposer); ok {
+				d.Dispose()
+			}
+		}
+	}
 }